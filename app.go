@@ -0,0 +1,76 @@
+package routix
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/static"
+)
+
+// APP is the context key holding the state of the server handling the request.
+const APP string = "ROUTIX_APP"
+
+// app is the request-time state of a server built by CreateServer.
+//
+// CreateServer configures the package globals while it builds a server, then
+// captures what requests need here and attaches it to every request, so
+// several servers (such as the apps of parallel tests) do not share it.
+type app struct {
+	engine         *gin.Engine
+	pathRoot       string
+	renderEnabled  bool
+	errorViews     map[int]string
+	errorResponder func(c *gin.Context, httpException exception.HttpExceptionResponse)
+	staticModules  []*static.Module
+	routes         []RouteInfo
+	shutdownHooks  []func()
+}
+
+var (
+	// createMu serializes CreateServer, which builds servers through the package globals.
+	createMu sync.Mutex
+	appsMu   sync.RWMutex
+	apps     = map[*gin.Engine]*app{}
+)
+
+// appOf returns the state of the server handling the request. Requests served
+// by an engine not built with CreateServer use the package globals.
+func appOf(c *gin.Context) *app {
+	if state, exists := c.Get(APP); exists {
+		return state.(*app)
+	}
+	return &app{
+		engine:         Driver,
+		pathRoot:       PathRoot,
+		renderEnabled:  IsEnableRender,
+		errorResponder: RespondError,
+	}
+}
+
+// RoutesOf returns the routes registered on the server built by CreateServer
+// that returned engine. Unlike Routes, it is not affected by servers created later.
+func RoutesOf(engine *gin.Engine) []RouteInfo {
+	appsMu.RLock()
+	defer appsMu.RUnlock()
+	if state, exists := apps[engine]; exists {
+		return append([]RouteInfo(nil), state.routes...)
+	}
+	return nil
+}
+
+// CloseServer runs the shutdown hooks of the server built by CreateServer that
+// returned engine, such as stopping view reloaders, without listening for
+// signals. Tests use it to release servers they created.
+func CloseServer(engine *gin.Engine) {
+	appsMu.Lock()
+	state, exists := apps[engine]
+	delete(apps, engine)
+	appsMu.Unlock()
+	if !exists {
+		return
+	}
+	for _, hook := range state.shutdownHooks {
+		hook()
+	}
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Options selects the sources Load reads, from lowest to highest precedence:
+// struct defaults, Files, EnvFile, environment variables and Args.
+type Options struct {
+	// Files are YAML, JSON or TOML files, chosen by extension. Later files
+	// override earlier ones and missing files are skipped.
+	Files []string
+	// EnvFile is a dotenv file. Its variables never override the real
+	// environment. Defaults to ".env"; a missing file is skipped.
+	EnvFile string
+	// EnvPrefix prefixes the derived environment variable names, such as "APP" for APP_SERVER_PORT.
+	EnvPrefix string
+	// Args are command line arguments parsed as flags, such as os.Args[1:].
+	// Flags are not read when Args is nil.
+	Args []string
+}
+
+// Errors aggregates every problem found while loading and validating a configuration.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "config: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the aggregated errors.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+var (
+	mu     sync.RWMutex
+	loaded = map[reflect.Type]any{}
+)
+
+// Load fills the struct pointed to by target from the sources of options, then validates it.
+//
+// Fields are configured with struct tags:
+//
+//	type AppConfig struct {
+//		Server   config.Server `config:"server"`
+//		Database struct {
+//			URL      string `config:"url" env:"DATABASE_URL" required:"true"`
+//			MaxConns int    `config:"max_conns" default:"10" validate:"min=1"`
+//		} `config:"database"`
+//	}
+//
+// The config tag names the key in files (nested under the parent's key) and
+// defaults to the field name in snake_case. Environment variables default to
+// the upper-cased key path joined by underscores (DATABASE_MAX_CONNS) and
+// flags to the key path joined by dots (-database.max_conns); the env and flag
+// tags override them. A field tagged required:"true" must end up non-zero and
+// validate tags are checked with go-playground/validator.
+//
+// Every problem is reported at once in an Errors value. The loaded value is
+// then available anywhere through Get.
+func Load(target any, options Options) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return errors.New("config: target must be a pointer to a struct")
+	}
+
+	fields := collectFields(value.Elem(), nil, options.EnvPrefix)
+	var problems Errors
+
+	// Defaults
+	for _, f := range fields {
+		if f.defaultValue != "" {
+			if err := setFromString(f.value, f.defaultValue); err != nil {
+				problems = append(problems, fmt.Errorf("%s: invalid default: %w", f.key, err))
+			}
+		}
+	}
+
+	// Config files
+	for _, file := range options.Files {
+		values, err := readFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		for _, f := range fields {
+			if raw, exists := lookup(values, f.path); exists {
+				if err := setFromAny(f.value, raw); err != nil {
+					problems = append(problems, fmt.Errorf("%s: %s: %w", file, f.key, err))
+				}
+			}
+		}
+	}
+
+	// .env file, then environment variables
+	envFile := options.EnvFile
+	if envFile == "" {
+		envFile = ".env"
+	}
+	dotenv, err := ReadEnvFile(envFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		problems = append(problems, fmt.Errorf("%s: %w", envFile, err))
+	}
+	for _, f := range fields {
+		raw, exists := os.LookupEnv(f.env)
+		if !exists {
+			raw, exists = dotenv[f.env]
+		}
+		if exists {
+			if err := setFromString(f.value, raw); err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+	}
+
+	// Flags
+	if options.Args != nil {
+		flags := flag.NewFlagSet("config", flag.ContinueOnError)
+		flags.SetOutput(discard{})
+		for _, f := range fields {
+			f := f
+			flags.Func(f.flag, f.key, func(raw string) error {
+				return setFromString(f.value, raw)
+			})
+		}
+		if err := flags.Parse(options.Args); err != nil {
+			problems = append(problems, fmt.Errorf("flags: %w", err))
+		}
+	}
+
+	// Validation
+	for _, f := range fields {
+		if f.required && f.value.IsZero() {
+			problems = append(problems, fmt.Errorf("%s is required (set %s or -%s)", f.key, f.env, f.flag))
+		}
+	}
+	if err := validate.Struct(target); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, fieldError := range validationErrors {
+				problems = append(problems, fmt.Errorf("%s failed on the %q rule", fieldError.Namespace(), fieldError.Tag()))
+			}
+		} else {
+			problems = append(problems, err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	loaded[value.Elem().Type()] = target
+	return nil
+}
+
+// MustLoad calls Load and panics when the configuration is invalid, so a
+// misconfigured application fails at startup.
+func MustLoad(target any, options Options) {
+	if err := Load(target, options); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns the configuration of type T loaded last, so controllers and
+// guards can read it without passing it around. It returns nil when no
+// configuration of that type was loaded.
+func Get[T any]() *T {
+	mu.RLock()
+	defer mu.RUnlock()
+	target, exists := loaded[reflect.TypeOf((*T)(nil)).Elem()]
+	if !exists {
+		return nil
+	}
+	return target.(*T)
+}
+
+// Set makes value the configuration of type T returned by Get, as if it had
+// been loaded. A nil value removes it. Tests use it to override the configuration.
+func Set[T any](value *T) {
+	mu.Lock()
+	defer mu.Unlock()
+	key := reflect.TypeOf((*T)(nil)).Elem()
+	if value == nil {
+		delete(loaded, key)
+		return
+	}
+	loaded[key] = value
+}
+
+var validate = validator.New()
+
+// readFile decodes a YAML, JSON or TOML file into a map.
+func readFile(file string) (map[string]any, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".json":
+		// Numbers are kept as written, as float64 would round integers above 2^53
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	case ".toml":
+		err = toml.Unmarshal(content, &values)
+	default:
+		err = fmt.Errorf("unsupported config file format %q", filepath.Ext(file))
+	}
+	return values, err
+}
+
+// lookup finds the value at path in nested maps, matching keys case-insensitively.
+func lookup(values map[string]any, path []string) (any, bool) {
+	var current any = values
+	for _, key := range path {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		found := false
+		for candidate, value := range object {
+			if strings.EqualFold(candidate, key) {
+				current, found = value, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
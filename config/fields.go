@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// field is a configurable leaf of the target struct.
+type field struct {
+	value        reflect.Value
+	path         []string
+	key          string
+	env          string
+	flag         string
+	defaultValue string
+	required     bool
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// collectFields walks the struct recursively and describes its configurable fields.
+// Anonymous embedded structs without a config tag are flattened into their parent.
+func collectFields(value reflect.Value, parent []string, envPrefix string) []field {
+	fields := []field{}
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		name, tagged := structField.Tag.Lookup("config")
+		if name == "-" {
+			continue
+		}
+		if !tagged {
+			name = snakeCase(structField.Name)
+		}
+
+		fieldValue := value.Field(i)
+		if isNested(structField.Type) {
+			path := append(append([]string(nil), parent...), name)
+			if structField.Anonymous && !tagged {
+				path = parent
+			}
+			fields = append(fields, collectFields(fieldValue, path, envPrefix)...)
+			continue
+		}
+
+		path := append(append([]string(nil), parent...), name)
+		key := strings.Join(path, ".")
+
+		env := structField.Tag.Get("env")
+		if env == "" {
+			env = strings.ToUpper(strings.Join(path, "_"))
+			if envPrefix != "" {
+				env = strings.ToUpper(envPrefix) + "_" + env
+			}
+		}
+
+		flagName := structField.Tag.Get("flag")
+		if flagName == "" {
+			flagName = key
+		}
+
+		fields = append(fields, field{
+			value:        fieldValue,
+			path:         path,
+			key:          key,
+			env:          env,
+			flag:         flagName,
+			defaultValue: structField.Tag.Get("default"),
+			required:     structField.Tag.Get("required") == "true",
+		})
+	}
+	return fields
+}
+
+// isNested reports whether a struct field holds settings of its own rather than a single value.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setFromString parses raw into the field according to its type. Slices are comma separated.
+func setFromString(value reflect.Value, raw string) error {
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		parts := []string{}
+		if strings.TrimSpace(raw) != "" {
+			parts = strings.Split(raw, ",")
+		}
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFromString(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	case reflect.Pointer:
+		element := reflect.New(value.Type().Elem())
+		if err := setFromString(element.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(element)
+	default:
+		return json.Unmarshal([]byte(raw), value.Addr().Interface())
+	}
+	return nil
+}
+
+// setFromAny assigns a value decoded from a config file to the field.
+// Scalars go through setFromString so durations and text types parse the same
+// way as in the environment; other values are converted through JSON.
+func setFromAny(value reflect.Value, raw any) error {
+	switch raw := raw.(type) {
+	case nil:
+		return nil
+	case string:
+		return setFromString(value, raw)
+	case json.Number:
+		// Exponents such as 1e7 are expanded for integer fields
+		if strings.ContainsAny(raw.String(), "eE") {
+			if number, err := raw.Float64(); err == nil {
+				return setFromString(value, strconv.FormatFloat(number, 'f', -1, 64))
+			}
+		}
+		return setFromString(value, raw.String())
+	case float64:
+		// fmt.Sprint would write 1e+07
+		return setFromString(value, strconv.FormatFloat(raw, 'f', -1, 64))
+	case bool, int, int64, uint64:
+		return setFromString(value, fmt.Sprint(raw))
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, value.Addr().Interface())
+}
+
+// snakeCase converts a Go field name such as DatabaseURL to database_url.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			previousLower := i > 0 && unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (previousLower || (nextLower && unicode.IsUpper(runes[i-1]))) {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
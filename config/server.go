@@ -0,0 +1,21 @@
+package config
+
+import (
+	"net"
+	"strconv"
+)
+
+// Server holds the settings routix reads to build its ServerConfig. Embed it in
+// your configuration struct and pass it to routix.ServerConfigFrom.
+type Server struct {
+	Port        int    `config:"port" default:"3000" validate:"min=1,max=65535"`
+	Host        string `config:"host"`
+	PathRoot    string `config:"path_root" default:"/"`
+	BaseViewDir string `config:"base_view_dir"`
+	DebugLogger bool   `config:"debug_logger"`
+}
+
+// Addr returns the address to listen on, such as ":3000".
+func (s Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
@@ -0,0 +1,267 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a parsed OpenAPI 3 document. Responses are checked against it
+// with Validate, or for every request by the Module middleware.
+type Document struct {
+	root       map[string]any
+	basePaths  []string
+	operations []*Operation
+}
+
+// Operation is an operation of a Document.
+type Operation struct {
+	// Method is the uppercase HTTP method, such as "GET".
+	Method string
+	// Path is the path template of the document, such as "/users/{id}".
+	Path string
+	// ID is the operationId, when the document sets one.
+	ID string
+
+	segments  []string
+	responses map[string]any
+}
+
+// String returns the method and path template of the operation.
+func (o *Operation) String() string {
+	return o.Method + " " + o.Path
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Load parses an OpenAPI 3 document written in JSON or YAML.
+func Load(data []byte) (*Document, error) {
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("contract: cannot parse document: %w", err)
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("contract: unsupported document version %q, expected OpenAPI 3", version)
+	}
+
+	d := &Document{root: normalize(root).(map[string]any)}
+	d.basePaths = serverPaths(d.root)
+
+	paths, _ := d.root["paths"].(map[string]any)
+	for template, item := range paths {
+		item, _ := d.resolve(item).(map[string]any)
+		for _, method := range methods {
+			operation, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			responses, _ := operation["responses"].(map[string]any)
+			id, _ := operation["operationId"].(string)
+			d.operations = append(d.operations, &Operation{
+				Method:    strings.ToUpper(method),
+				Path:      template,
+				ID:        id,
+				segments:  splitSegments(template),
+				responses: responses,
+			})
+		}
+	}
+	// Literal segments win over parameters, so /users/me is matched before /users/{id}
+	sort.SliceStable(d.operations, func(i, j int) bool {
+		return literalCount(d.operations[i].segments) > literalCount(d.operations[j].segments)
+	})
+	return d, nil
+}
+
+// LoadFile parses the OpenAPI 3 document stored at path.
+func LoadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("contract: %w", err)
+	}
+	return Load(data)
+}
+
+// LoadFS parses the OpenAPI 3 document named name in fsys, such as an embed.FS.
+func LoadFS(fsys fs.FS, name string) (*Document, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("contract: %w", err)
+	}
+	return Load(data)
+}
+
+// MustLoadFile is like LoadFile but panics when the document cannot be loaded,
+// for package-level variables and test setup.
+func MustLoadFile(path string) *Document {
+	d, err := LoadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Operations returns the operations of the document.
+func (d *Document) Operations() []*Operation {
+	return append([]*Operation(nil), d.operations...)
+}
+
+// Operation returns the operation matching a request method and URL path, or
+// nil when the document does not describe it. The path of the first server
+// URL, such as "/api" in "https://example.com/api", may prefix urlPath.
+func (d *Document) Operation(method string, urlPath string) *Operation {
+	candidates := []string{urlPath}
+	for _, basePath := range d.basePaths {
+		if trimmed, ok := strings.CutPrefix(urlPath, basePath); ok && (trimmed == "" || trimmed[0] == '/') {
+			candidates = append([]string{trimmed}, candidates...)
+		}
+	}
+	for _, candidate := range candidates {
+		segments := splitSegments(candidate)
+		for _, operation := range d.operations {
+			if operation.Method == method && matchSegments(operation.segments, segments) {
+				return operation
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows the $ref of node within the document, up to a bounded depth
+// so that cyclic references cannot loop.
+func (d *Document) resolve(node any) any {
+	for depth := 0; depth < 32; depth++ {
+		object, ok := node.(map[string]any)
+		if !ok {
+			return node
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return node
+		}
+		target, found := d.pointer(ref)
+		if !found {
+			return nil
+		}
+		node = target
+	}
+	return nil
+}
+
+// pointer returns the node at a local reference such as "#/components/schemas/User".
+func (d *Document) pointer(ref string) (any, bool) {
+	fragment, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, false
+	}
+	var node any = d.root
+	for _, token := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = object[token]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// serverPaths returns the path of each server URL other than "/".
+func serverPaths(root map[string]any) []string {
+	servers, _ := root["servers"].([]any)
+	var paths []string
+	for _, server := range servers {
+		server, _ := server.(map[string]any)
+		raw, _ := server["url"].(string)
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		if basePath := strings.TrimSuffix(parsed.Path, "/"); basePath != "" {
+			paths = append(paths, basePath)
+		}
+	}
+	return paths
+}
+
+func splitSegments(urlPath string) []string {
+	return strings.Split(strings.Trim(urlPath, "/"), "/")
+}
+
+// matchSegments reports whether the segments of a request path match the segments of a template.
+func matchSegments(template []string, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, segment := range template {
+		if isParameter(segment) {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func literalCount(segments []string) int {
+	count := 0
+	for _, segment := range segments {
+		if !isParameter(segment) {
+			count++
+		}
+	}
+	return count
+}
+
+// normalize turns the values decoded from YAML into the values decoded from
+// JSON: keys become strings and integers become json.Number.
+func normalize(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = normalize(item)
+		}
+		return value
+	case map[any]any:
+		object := make(map[string]any, len(value))
+		for key, item := range value {
+			object[fmt.Sprint(key)] = normalize(item)
+		}
+		return object
+	case []any:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+		return value
+	case int:
+		return json.Number(fmt.Sprint(value))
+	case int64:
+		return json.Number(fmt.Sprint(value))
+	case uint64:
+		return json.Number(fmt.Sprint(value))
+	case float64:
+		return json.Number(fmt.Sprint(value))
+	default:
+		return value
+	}
+}
@@ -0,0 +1,105 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/logger"
+)
+
+// DIAGNOSTICS is the context key holding the diagnostics of the response, once written.
+const DIAGNOSTICS string = "ROUTIX_CONTRACT_DIAGNOSTICS"
+
+// Config holds the settings of the contract module.
+type Config struct {
+	Options
+	// OnDrift receives the diagnostics of each response that differs from the
+	// document. Defaults to logging them with the "Contract" logger.
+	OnDrift func(c *gin.Context, diagnostics []Diagnostic)
+	// Always validates in release mode too. By default the module only runs in
+	// debug mode, since it buffers every response body.
+	Always bool
+}
+
+// Module validates the responses of a server against a Document.
+type Module struct {
+	document *Document
+	config   Config
+}
+
+// New creates a contract module for document. Mount it with ServerConfig.Contract.
+func New(document *Document, config Config) *Module {
+	if config.OnDrift == nil {
+		config.OnDrift = logDrift
+	}
+	return &Module{document: document, config: config}
+}
+
+// Document returns the document responses are validated against.
+func (m *Module) Document() *Document {
+	return m.document
+}
+
+// Enabled reports whether the module validates responses in the current gin mode.
+func (m *Module) Enabled() bool {
+	return m.config.Always || gin.IsDebugging()
+}
+
+// Middleware records the body of every response and validates the response
+// against the document once the handlers are done.
+func (m *Module) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if writer.truncated {
+			return
+		}
+
+		diagnostics := m.document.Validate(c.Request.Method, c.Request.URL.Path, writer.Status(), writer.Header(), writer.body.Bytes(), m.config.Options)
+		if len(diagnostics) > 0 {
+			c.Set(DIAGNOSTICS, diagnostics)
+			m.config.OnDrift(c, diagnostics)
+		}
+	}
+}
+
+// logDrift logs each diagnostic as a warning.
+func logDrift(c *gin.Context, diagnostics []Diagnostic) {
+	log := logger.Logger("Contract")
+	for _, diagnostic := range diagnostics {
+		log.Warning(fmt.Sprint(diagnostic))
+	}
+}
+
+// maxRecordedBody bounds the body kept for validation, so that streams and
+// large files are passed through and skipped.
+const maxRecordedBody = 4 << 20
+
+// recordingWriter keeps a copy of the body written through it.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if w.truncated || w.body.Len()+len(data) > maxRecordedBody {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
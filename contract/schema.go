@@ -0,0 +1,410 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// schema checks value against a schema node of the document and reports the
+// differences under pointer.
+func (v *validator) schema(node any, value any, pointer string) {
+	schema, ok := v.document.resolve(node).(map[string]any)
+	if !ok {
+		if node != nil {
+			v.report(InvalidDocument, pointer, "the schema cannot be resolved")
+		}
+		return
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, member := range all {
+			v.schemaWithin(member, value, pointer)
+		}
+	}
+	if one, ok := schema["oneOf"].([]any); ok {
+		v.alternatives(one, value, pointer, true)
+	}
+	if some, ok := schema["anyOf"].([]any); ok {
+		v.alternatives(some, value, pointer, false)
+	}
+
+	if value == nil {
+		if !nullable(schema) && hasType(schema) {
+			v.report(TypeMismatch, pointer, "expected %s, got null", typeNames(schema))
+		}
+		return
+	}
+	if hasType(schema) && !matchesType(schema, value) {
+		v.report(TypeMismatch, pointer, "expected %s, got %s", typeNames(schema), jsonType(value))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, value) {
+		v.report(EnumMismatch, pointer, "%s is not one of %s", format(value), format(enum))
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		v.object(schema, value, pointer)
+	case []any:
+		v.array(schema, value, pointer)
+	case string:
+		v.string(schema, value, pointer)
+	case json.Number:
+		v.number(schema, value, pointer)
+	}
+}
+
+// schemaWithin checks value against a member of an allOf. Members usually
+// describe part of an object, so the object's fields are checked for extras
+// once, against the properties of every member, rather than by each member.
+func (v *validator) schemaWithin(member any, value any, pointer string) {
+	previous, wasOpen := v.open, v.isOpen
+	v.open, v.isOpen = pointer, true
+	v.schema(member, value, pointer)
+	v.open, v.isOpen = previous, wasOpen
+}
+
+// alternatives checks value against oneOf or anyOf members. When no member
+// matches, the differences of the closest member are reported.
+func (v *validator) alternatives(members []any, value any, pointer string, exclusive bool) {
+	var closest []Diagnostic
+	matches := 0
+	for _, member := range members {
+		trial := &validator{document: v.document, options: v.options, operation: v.operation, status: v.status}
+		trial.schema(member, value, pointer)
+		if len(trial.diagnostics) == 0 {
+			matches++
+			continue
+		}
+		if closest == nil || len(trial.diagnostics) < len(closest) {
+			closest = trial.diagnostics
+		}
+	}
+	switch {
+	case matches == 0:
+		v.diagnostics = append(v.diagnostics, closest...)
+	case exclusive && matches > 1:
+		v.report(ConstraintViolation, pointer, "matches %d schemas of oneOf, expected exactly one", matches)
+	}
+}
+
+// object checks the properties of an object.
+func (v *validator) object(schema map[string]any, object map[string]any, pointer string) {
+	properties := v.properties(schema)
+
+	required, _ := schema["required"].([]any)
+	for _, name := range required {
+		name, _ := name.(string)
+		if _, exists := object[name]; !exists {
+			v.report(MissingField, joinPointer(pointer, name), "required field %q is missing", name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	additional, hasAdditional := schema["additionalProperties"]
+	for _, name := range names {
+		childPointer := joinPointer(pointer, name)
+		if property, declared := properties[name]; declared {
+			v.schema(property, object[name], childPointer)
+			continue
+		}
+		switch {
+		case additional == false:
+			v.report(ExtraField, childPointer, "field %q is not declared and additionalProperties is false", name)
+		case hasAdditional && additional != true:
+			v.schema(additional, object[name], childPointer)
+		case hasAdditional, v.options.AllowExtraFields, len(properties) == 0, v.isOpen && pointer == v.open, hasAlternatives(schema):
+			// Open and free-form objects accept any field, and allOf members and
+			// oneOf or anyOf alternatives leave the check to the composition
+		default:
+			v.report(ExtraField, childPointer, "field %q is not declared by the schema", name)
+		}
+	}
+
+	v.bounds(schema, pointer, "properties", float64(len(object)), "minProperties", "maxProperties")
+}
+
+// properties returns the properties declared by a schema and the members of its allOf.
+func (v *validator) properties(node any) map[string]any {
+	schema, _ := v.document.resolve(node).(map[string]any)
+	properties := map[string]any{}
+	if own, ok := schema["properties"].(map[string]any); ok {
+		for name, property := range own {
+			properties[name] = property
+		}
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, member := range all {
+			for name, property := range v.properties(member) {
+				if _, exists := properties[name]; !exists {
+					properties[name] = property
+				}
+			}
+		}
+	}
+	return properties
+}
+
+// array checks the items of an array.
+func (v *validator) array(schema map[string]any, array []any, pointer string) {
+	if items, ok := schema["items"]; ok {
+		for i, item := range array {
+			v.schema(items, item, joinPointer(pointer, strconv.Itoa(i)))
+		}
+	}
+	v.bounds(schema, pointer, "items", float64(len(array)), "minItems", "maxItems")
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		seen := map[string]bool{}
+		for _, item := range array {
+			key := format(item)
+			if seen[key] {
+				v.report(ConstraintViolation, pointer, "item %s is repeated but uniqueItems is true", key)
+				break
+			}
+			seen[key] = true
+		}
+	}
+}
+
+// string checks the length, pattern and format of a string.
+func (v *validator) string(schema map[string]any, value string, pointer string) {
+	v.bounds(schema, pointer, "characters", float64(utf8.RuneCountInString(value)), "minLength", "maxLength")
+	if pattern, ok := schema["pattern"].(string); ok {
+		expression, err := compilePattern(pattern)
+		if err != nil {
+			v.report(InvalidDocument, pointer, "pattern %q cannot be compiled: %v", pattern, err)
+		} else if !expression.MatchString(value) {
+			v.report(ConstraintViolation, pointer, "%q does not match pattern %q", value, pattern)
+		}
+	}
+	if name, ok := schema["format"].(string); ok {
+		if check, known := formats[name]; known && !check(value) {
+			v.report(ConstraintViolation, pointer, "%q is not a valid %s", value, name)
+		}
+	}
+}
+
+// number checks the range and multipleOf of a number.
+func (v *validator) number(schema map[string]any, value json.Number, pointer string) {
+	number, err := value.Float64()
+	if err != nil {
+		return
+	}
+	for _, limit := range []struct {
+		keyword   string
+		exclusive string
+		below     bool
+	}{{"minimum", "exclusiveMinimum", true}, {"maximum", "exclusiveMaximum", false}} {
+		bound, hasBound := toFloat(schema[limit.keyword])
+		// OpenAPI 3.0 marks exclusive bounds with a boolean, 3.1 with a number
+		exclusive, _ := schema[limit.exclusive].(bool)
+		if exclusiveBound, ok := toFloat(schema[limit.exclusive]); ok {
+			bound, hasBound, exclusive = exclusiveBound, true, true
+		}
+		if !hasBound {
+			continue
+		}
+		violated := number < bound || (exclusive && number == bound)
+		if !limit.below {
+			violated = number > bound || (exclusive && number == bound)
+		}
+		if violated {
+			keyword := limit.keyword
+			if exclusive {
+				keyword = limit.exclusive
+			}
+			v.report(ConstraintViolation, pointer, "%s breaks %s %s", value, keyword, format(bound))
+		}
+	}
+	if multiple, ok := toFloat(schema["multipleOf"]); ok && multiple > 0 {
+		if quotient := number / multiple; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.report(ConstraintViolation, pointer, "%s is not a multiple of %s", value, format(multiple))
+		}
+	}
+}
+
+// bounds checks a size against the minimum and maximum keywords of a schema.
+func (v *validator) bounds(schema map[string]any, pointer string, unit string, size float64, minKeyword string, maxKeyword string) {
+	if minimum, ok := toFloat(schema[minKeyword]); ok && size < minimum {
+		v.report(ConstraintViolation, pointer, "has %s %s, %s is %s", format(size), unit, minKeyword, format(minimum))
+	}
+	if maximum, ok := toFloat(schema[maxKeyword]); ok && size > maximum {
+		v.report(ConstraintViolation, pointer, "has %s %s, %s is %s", format(size), unit, maxKeyword, format(maximum))
+	}
+}
+
+// types returns the types a schema allows: "type" is a string in OpenAPI 3.0
+// and may be a list in 3.1.
+func types(schema map[string]any) []string {
+	switch declared := schema["type"].(type) {
+	case string:
+		return []string{declared}
+	case []any:
+		names := make([]string, 0, len(declared))
+		for _, name := range declared {
+			if name, ok := name.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func hasType(schema map[string]any) bool {
+	return len(types(schema)) > 0
+}
+
+func typeNames(schema map[string]any) string {
+	return strings.Join(types(schema), " or ")
+}
+
+func nullable(schema map[string]any) bool {
+	if flag, _ := schema["nullable"].(bool); flag {
+		return true
+	}
+	for _, name := range types(schema) {
+		if name == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAlternatives(schema map[string]any) bool {
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		if _, ok := schema[keyword]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesType reports whether a decoded JSON value has one of the types of a schema.
+func matchesType(schema map[string]any, value any) bool {
+	actual := jsonType(value)
+	for _, name := range types(schema) {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the schema type of a decoded JSON value.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		if number, err := value.Float64(); err == nil && number == math.Trunc(number) && !strings.ContainsAny(string(value), ".eE") {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsValue(values []any, value any) bool {
+	encoded := format(value)
+	for _, candidate := range values {
+		if format(candidate) == encoded {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		number, err := value.Float64()
+		return number, err == nil
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// format encodes a value as JSON for messages and comparisons. Numbers are
+// compared by value, so 1 and 1.0 are equal.
+func format(value any) string {
+	if number, ok := value.(json.Number); ok {
+		if parsed, err := number.Float64(); err == nil {
+			return strconv.FormatFloat(parsed, 'g', -1, 64)
+		}
+	}
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// joinPointer appends a token to a JSON pointer, escaping "~" and "/".
+func joinPointer(pointer string, token string) string {
+	return pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+var patterns sync.Map
+
+// compilePattern compiles a schema pattern once.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if expression, ok := patterns.Load(pattern); ok {
+		return expression.(*regexp.Regexp), nil
+	}
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, expression)
+	return expression, nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// formats checks the string formats whose values are easy to get wrong in
+// code. Other formats are accepted as is.
+var formats = map[string]func(value string) bool{
+	"date-time": func(value string) bool {
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	},
+	"date": func(value string) bool {
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+	"email": func(value string) bool {
+		local, domain, found := strings.Cut(value, "@")
+		return found && local != "" && strings.Contains(domain, ".") && !strings.ContainsAny(value, " \t")
+	},
+}
@@ -0,0 +1,221 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind classifies a Diagnostic.
+type Kind string
+
+const (
+	// UndocumentedOperation is a request whose method and path match no operation.
+	UndocumentedOperation Kind = "undocumented_operation"
+	// UndocumentedStatus is a status the operation does not list, nor a range or default covering it.
+	UndocumentedStatus Kind = "undocumented_status"
+	// UndocumentedContentType is a content type the response does not list.
+	UndocumentedContentType Kind = "undocumented_content_type"
+	// UndocumentedBody is a body sent for a response documented without content.
+	UndocumentedBody Kind = "undocumented_body"
+	// MissingBody is an empty body for a response documented with content.
+	MissingBody Kind = "missing_body"
+	// InvalidBody is a body that cannot be decoded for its content type.
+	InvalidBody Kind = "invalid_body"
+	// TypeMismatch is a value of another type than its schema.
+	TypeMismatch Kind = "type_mismatch"
+	// MissingField is a required property absent from an object.
+	MissingField Kind = "missing_field"
+	// ExtraField is a property its object schema does not declare.
+	ExtraField Kind = "extra_field"
+	// EnumMismatch is a value outside the enum of its schema.
+	EnumMismatch Kind = "enum_mismatch"
+	// ConstraintViolation is a value breaking a constraint such as maxLength, pattern or minItems.
+	ConstraintViolation Kind = "constraint_violation"
+	// InvalidDocument is a part of the document that cannot be used, such as an external $ref.
+	InvalidDocument Kind = "invalid_document"
+)
+
+// Diagnostic describes a difference between a response and the document.
+type Diagnostic struct {
+	// Kind classifies the difference.
+	Kind Kind `json:"kind"`
+	// Operation is the matched operation, such as "GET /users/{id}", or the
+	// request method and path when none matched.
+	Operation string `json:"operation"`
+	// Status is the status code of the response.
+	Status int `json:"status"`
+	// Pointer is the JSON pointer of the value in the body, such as
+	// "/items/0/name". It is empty for differences of the whole response.
+	Pointer string `json:"pointer,omitempty"`
+	// Message describes the difference.
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic on one line.
+func (d Diagnostic) String() string {
+	location := ""
+	if d.Pointer != "" {
+		location = " at " + d.Pointer
+	}
+	return fmt.Sprintf("%s %d: %s%s: %s", d.Operation, d.Status, d.Kind, location, d.Message)
+}
+
+// Options tunes validation.
+type Options struct {
+	// AllowExtraFields accepts properties not declared by object schemas. By
+	// default they are reported as ExtraField unless the schema sets
+	// additionalProperties, so that fields added in code reach the document.
+	AllowExtraFields bool
+	// IgnoreUndocumented skips requests matching no operation instead of
+	// reporting UndocumentedOperation.
+	IgnoreUndocumented bool
+}
+
+// Validate checks a response against the operation matching method and
+// urlPath, and returns the differences found. It returns nil when the response
+// follows the document. HEAD requests are checked against the GET operation
+// when the document has no HEAD one, without their body.
+func (d *Document) Validate(method string, urlPath string, status int, header http.Header, body []byte, options Options) []Diagnostic {
+	operation := d.Operation(method, urlPath)
+	if operation == nil && method == http.MethodHead {
+		operation = d.Operation(http.MethodGet, urlPath)
+	}
+	if operation == nil {
+		if options.IgnoreUndocumented {
+			return nil
+		}
+		return []Diagnostic{{
+			Kind:      UndocumentedOperation,
+			Operation: method + " " + urlPath,
+			Status:    status,
+			Message:   "the document has no operation for this request",
+		}}
+	}
+
+	v := &validator{document: d, options: options, operation: operation.String(), status: status}
+	v.response(operation, header.Get("Content-Type"), body, method != http.MethodHead)
+	return v.diagnostics
+}
+
+type validator struct {
+	document    *Document
+	options     Options
+	operation   string
+	status      int
+	diagnostics []Diagnostic
+	// open is the pointer of the object validated against an allOf member,
+	// whose fields are checked for extras by the allOf itself, when isOpen is set.
+	open   string
+	isOpen bool
+}
+
+func (v *validator) report(kind Kind, pointer string, format string, args ...any) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Kind:      kind,
+		Operation: v.operation,
+		Status:    v.status,
+		Pointer:   pointer,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// response checks the status of a response, then its content type and body when hasBody is set.
+func (v *validator) response(operation *Operation, contentType string, body []byte, hasBody bool) {
+	documented, found := v.lookupResponse(operation.responses)
+	if !found {
+		v.report(UndocumentedStatus, "", "status %d is not documented, documented: %s", v.status, strings.Join(sortedKeys(operation.responses), ", "))
+		return
+	}
+	response, ok := v.document.resolve(documented).(map[string]any)
+	if !ok {
+		v.report(InvalidDocument, "", "the response of status %d cannot be resolved", v.status)
+		return
+	}
+
+	content, _ := response["content"].(map[string]any)
+	if !hasBody {
+		return
+	}
+	if len(content) == 0 {
+		if len(body) > 0 {
+			v.report(UndocumentedBody, "", "status %d is documented without content, got %d bytes of %s", v.status, len(body), contentType)
+		}
+		return
+	}
+	if len(body) == 0 {
+		if v.status != http.StatusNoContent && v.status != http.StatusNotModified {
+			v.report(MissingBody, "", "status %d is documented with %s content, got an empty body", v.status, strings.Join(sortedKeys(content), ", "))
+		}
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(contentType)
+	}
+	media, found := lookupMediaType(content, mediaType)
+	if !found {
+		v.report(UndocumentedContentType, "", "content type %q is not documented, documented: %s", mediaType, strings.Join(sortedKeys(content), ", "))
+		return
+	}
+	schema := media["schema"]
+	if schema == nil || !isJSON(mediaType) {
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		v.report(InvalidBody, "", "the body is not valid JSON: %v", err)
+		return
+	}
+	v.schema(schema, value, "")
+}
+
+// lookupResponse returns the response documented for the status: its code,
+// then its range such as "4XX", then the default response.
+func (v *validator) lookupResponse(responses map[string]any) (any, bool) {
+	code := strconv.Itoa(v.status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := responses[key]; ok {
+			return response, true
+		}
+	}
+	return nil, false
+}
+
+// lookupMediaType returns the content documented for a media type, then for
+// its wildcards such as "application/*" and "*/*".
+func lookupMediaType(content map[string]any, mediaType string) (map[string]any, bool) {
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, major + "/*", "*/*"} {
+		for documented, media := range content {
+			if strings.EqualFold(documented, key) {
+				media, _ := media.(map[string]any)
+				return media, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// isJSON reports whether a media type is JSON, such as application/json or application/problem+json.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
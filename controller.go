@@ -2,12 +2,18 @@ package routix
 
 import (
 	"fmt"
-	"net/http"
+	"path"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/guard"
+	"github.com/l1ttps/routix/interceptor"
 	"github.com/l1ttps/routix/logger"
+	"github.com/l1ttps/routix/metadata"
+	"github.com/l1ttps/routix/pipe"
+	"github.com/l1ttps/routix/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Engine *gin.Engine
@@ -34,7 +40,20 @@ func Controller(basePath string, routes ...RouteBase) {
 		"PATCH":  c.PATCH,
 	}
 
+	// Collect the middlewares applied to the whole controller with Use
+	controllerMiddlewares := []gin.HandlerFunc{}
 	for _, route := range routes {
+		if route.method == "" {
+			controllerMiddlewares = append(controllerMiddlewares, route.middlewares...)
+		}
+	}
+
+	for _, route := range routes {
+		if route.method == "" {
+			continue
+		}
+		route.middlewares = append(append([]gin.HandlerFunc(nil), controllerMiddlewares...), route.middlewares...)
+
 		handlerFunc, exists := methodMap[route.method]
 		if !exists {
 			fmt.Printf("Invalid HTTP method: %s\n", route.method)
@@ -44,8 +63,11 @@ func Controller(basePath string, routes ...RouteBase) {
 
 		logInitController(route.basePath, route.method, strings.Replace(controllerAbsolutePath, "/", "", -1))
 
-		// apply middlewares and interceptor for each controller route
-		handlerFunc(route.basePath, append(route.middlewares, PipeResponse(route.handler))...)
+		info := registerRoute(c.BasePath(), route)
+
+		// apply metadata, middlewares and interceptor for each controller route
+		handlers := append([]gin.HandlerFunc{applyMetadata(info.Metadata)}, route.middlewares...)
+		handlerFunc(route.basePath, append(handlers, PipeResponse(route.handler))...)
 	}
 
 }
@@ -54,52 +76,69 @@ func Controller(basePath string, routes ...RouteBase) {
 //
 // The handler function is responsible for processing a gin.Context and returning a response.
 // The function checks the type of the response:
-// - If the response is an HttpExceptionResponse, it returns a JSON response with the status code and message.
-// - If the response is a map[string]interface{}, it extracts the status code and message from the map and returns a JSON response.
-// - Otherwise, it returns a JSON response with the response itself.
+// - If the response is a Responder (such as *Response or *ViewResponse), it lets the response write itself.
+// - If the response is an HttpExceptionResponse, it responds with the status code and message, or with its error view (see ErrorView).
+// - If the route has a Render view, it renders the response in it.
+// - If the response is a map[string]interface{}, it extracts the status code and message from the map and responds with them.
+// - Otherwise, it responds with the response itself, using the route's HttpCode or 200.
+//
+// Responses are serialized with the encoder negotiated from the Accept header (see Produces).
+//
+// When a param pipe of the route failed (see the pipe package), the handler is
+// skipped and the pipe's BadRequestException is written instead.
 func PipeResponse(handler func(c *gin.Context) interface{}) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		response := handler(ctx)
-
-		render, exists := ctx.Get(RENDER)
-		if exists {
-			if IsEnableRender {
-				ctx.HTML(http.StatusOK, render.(string), response)
-			} else {
-				ctx.JSON(http.StatusNotFound, gin.H{
-					"status":  http.StatusNotFound,
-					"message": "Cannot find view: " + render.(string),
-				})
-			}
-			ctx.Abort()
+		if httpException, failed := pipe.Failure(ctx); failed {
+			writeResponse(ctx, httpException)
 			return
 		}
 
-		// Override exception to response with status and message
-		if httpException, ok := response.(exception.HttpExceptionResponse); ok {
-			ctx.JSON(httpException.Status, gin.H{
-				"status":  httpException.Status,
-				"message": httpException.Message,
+		var response interface{}
+		tracing.Span(ctx, "handler "+ctx.FullPath(), func(trace.Span) {
+			response = handler(ctx)
+		})
+
+		tracing.Span(ctx, "serialize", func(trace.Span) {
+			writeResponse(ctx, response)
+		})
+	}
+}
+
+// writeResponse writes the value returned by a handler as described by PipeResponse.
+func writeResponse(ctx *gin.Context, response interface{}) {
+	// Return values that describe the whole response write it themselves
+	if responder, ok := response.(Responder); ok {
+		responder.Respond(ctx)
+		return
+	}
+
+	// Override exception to response with status and message, or with an error view
+	if httpException, ok := response.(exception.HttpExceptionResponse); ok {
+		appOf(ctx).errorResponder(ctx, httpException)
+		return
+	}
+
+	// Render the view of the route, or its data for clients preferring another media type
+	if render, exists := ctx.Get(RENDER); exists {
+		renderView(ctx, DefaultStatus(ctx), render.(string), response)
+		return
+	}
+
+	// Response status code and message from handler
+	if status, ok := response.(map[string]interface{}); ok {
+		statusCode, exists := status["status"].(int)
+		message, messageExists := status["message"].(string)
+		if exists && messageExists {
+			respond(ctx, statusCode, gin.H{
+				"status":  statusCode,
+				"message": message,
 			})
 			return
 		}
-
-		// Response status code and message from handler
-		if status, ok := response.(map[string]interface{}); ok {
-			statusCode, exists := status["status"].(int)
-			message, messageExists := status["message"].(string)
-			if exists && messageExists {
-				ctx.JSON(statusCode, gin.H{
-					"status":  statusCode,
-					"message": message,
-				})
-				return
-			}
-		}
-
-		// response default to JSON
-		ctx.JSON(http.StatusOK, response)
 	}
+
+	// response default to the negotiated media type, JSON when the client accepts anything
+	respondWithBody(ctx, DefaultStatus(ctx), response)
 }
 
 // logInitController initializes the logging for a controller in the given base path and HTTP method.
@@ -113,6 +152,93 @@ func logInitController(basePath string, method HTTPMethod, controllerAbsolutePat
 	log.Success(fmt.Sprintf("{%s} Mapped {%s, %s} route", controllerAbsolutePath, basePath, method))
 }
 
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Method is the HTTP method of the route.
+	Method HTTPMethod
+	// Path is the full route template, including PathRoot and the controller path.
+	Path string
+	// Controller is the absolute path of the controller the route belongs to.
+	Controller string
+	// Metadata holds the static metadata recorded on the route's middlewares.
+	Metadata map[string]any
+	// Guards lists the guard functions protecting the route, controller guards first.
+	Guards []string
+	// Interceptors lists the interceptor functions of the route, in the order they run.
+	Interceptors []string
+}
+
+// registeredRoutes holds the routes mapped by Controller since the server was created.
+var registeredRoutes []RouteInfo
+
+// Routes returns the routes registered on the current server, in registration order.
+//
+// Documentation generators and framework modules use it to inspect routes and
+// their metadata without serving a request.
+func Routes() []RouteInfo {
+	return append([]RouteInfo(nil), registeredRoutes...)
+}
+
+// registerRoute records route under the controller group path and returns its description.
+func registerRoute(controllerPath string, route RouteBase) RouteInfo {
+	info := newRouteInfo(route.method, joinPaths(controllerPath, route.basePath), controllerPath, route.middlewares)
+	registeredRoutes = append(registeredRoutes, info)
+	return info
+}
+
+// newRouteInfo describes a route from the middlewares it runs.
+func newRouteInfo(method HTTPMethod, absolutePath string, controllerPath string, handlers []gin.HandlerFunc) RouteInfo {
+	info := RouteInfo{
+		Method:       method,
+		Path:         absolutePath,
+		Controller:   controllerPath,
+		Metadata:     metadata.Collect(handlers...),
+		Guards:       collectNames(guard.GUARDS, handlers),
+		Interceptors: collectNames(interceptor.INTERCEPTORS, handlers),
+	}
+	// Several guard and interceptor middlewares add up instead of overriding each other
+	if info.Guards != nil {
+		info.Metadata[guard.GUARDS] = info.Guards
+	}
+	if info.Interceptors != nil {
+		info.Metadata[interceptor.INTERCEPTORS] = info.Interceptors
+	}
+	return info
+}
+
+// collectNames concatenates the function names recorded under key on handlers.
+func collectNames(key string, handlers []gin.HandlerFunc) []string {
+	var names []string
+	for _, handler := range handlers {
+		if recorded, ok := metadata.Of(handler)[key].([]string); ok {
+			names = append(names, recorded...)
+		}
+	}
+	return names
+}
+
+// applyMetadata returns a middleware that exposes the route metadata in the request
+// context before any other route middleware runs.
+func applyMetadata(values map[string]any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for key, value := range values {
+			c.Set(key, value)
+		}
+	}
+}
+
+// joinPaths joins a group path and a route path the same way gin does.
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
 type RouteBase struct {
 	basePath    string
 	handler     func(c *gin.Context) any
@@ -158,6 +284,19 @@ func NewRouteBase(basePath string, handler func(c *gin.Context) any, method HTTP
 	}
 }
 
+// Use applies middlewares to every route of a controller, before each route's own middlewares.
+//
+// It is passed to Controller alongside the routes, and is the place for
+// controller-wide guards, interceptors and metadata:
+//
+//	Controller("/admin",
+//		Use(guard.UseGuard(guards.AdminGuard)),
+//		Get("/users", listUsers),
+//	)
+func Use(middlewares ...gin.HandlerFunc) RouteBase {
+	return RouteBase{middlewares: middlewares}
+}
+
 // Get returns a new RouteBase with the given base path, handler function, and
 // optional middlewares.
 //
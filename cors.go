@@ -0,0 +1,76 @@
+package routix
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/cors"
+)
+
+var (
+	// globalCORS is the policy built from ServerConfig.CORS, nil when CORS is not configured.
+	globalCORS *cors.Policy
+	// routeCORS maps "METHOD path" of every registered route to its effective policy.
+	routeCORS map[string]*cors.Policy
+)
+
+// useCORS sets the global policy and applies the middleware adding CORS headers to actual requests.
+func useCORS(config *cors.Config) {
+	globalCORS = nil
+	if config != nil {
+		globalCORS = cors.New(*config)
+	}
+	routeCORS = map[string]*cors.Policy{}
+
+	// routes is filled by mountPreflights once the routes of this server are registered
+	global, routes := globalCORS, routeCORS
+	Driver.Use(func(c *gin.Context) {
+		if c.Request.Method == http.MethodOptions {
+			return
+		}
+		policy, exists := routes[c.Request.Method+" "+c.FullPath()]
+		if !exists {
+			policy = global
+		}
+		if policy != nil {
+			policy.Apply(c)
+		}
+	})
+}
+
+// mountPreflights resolves the policy of every registered route, then mounts
+// an OPTIONS handler on each path that has one.
+//
+// A route uses the policy set with cors.Override in its metadata, or the global
+// policy. The preflight of a path uses the first override found among its routes.
+func mountPreflights() {
+	paths := []string{}
+	methods := map[string][]string{}
+	policies := map[string]*cors.Policy{}
+
+	for _, route := range registeredRoutes {
+		policy := globalCORS
+		if override, exists := route.Metadata[cors.CONFIG]; exists {
+			policy = override.(*cors.Policy)
+		}
+		routeCORS[string(route.Method)+" "+route.Path] = policy
+
+		if _, exists := methods[route.Path]; !exists {
+			paths = append(paths, route.Path)
+		}
+		methods[route.Path] = append(methods[route.Path], string(route.Method))
+		if _, exists := policies[route.Path]; !exists || policies[route.Path] == globalCORS {
+			policies[route.Path] = policy
+		}
+	}
+
+	for _, path := range paths {
+		policy, allowed := policies[path], methods[path]
+		if policy == nil {
+			continue
+		}
+		Driver.OPTIONS(path, func(c *gin.Context) {
+			policy.Preflight(c, allowed)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package cors
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/metadata"
+)
+
+// CONFIG is the metadata key holding the CORS policy overriding the global one.
+const CONFIG string = "ROUTIX_CORS"
+
+// Config describes which cross-origin requests are allowed.
+type Config struct {
+	// AllowOrigins lists allowed origins: exact ("https://app.example.com"),
+	// wildcard ("https://*.example.com") or "*" for any origin.
+	AllowOrigins []string
+	// AllowOriginPatterns lists regular expressions matched against the whole origin.
+	AllowOriginPatterns []string
+	// AllowOriginFunc decides for origins not matched by the lists above.
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods restricts the methods allowed in preflight responses.
+	// Defaults to the methods registered for the requested path.
+	AllowMethods []string
+	// AllowHeaders lists the request headers allowed in preflight responses.
+	// Defaults to echoing the headers the client asks for.
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers readable by the client.
+	ExposeHeaders []string
+	// AllowCredentials allows cookies and authorization headers. It cannot be
+	// combined with the "*" origin, which would let any site read credentialed
+	// responses.
+	AllowCredentials bool
+	// MaxAge is how long clients may cache preflight responses.
+	MaxAge time.Duration
+}
+
+// Policy is a compiled CORS configuration.
+type Policy struct {
+	config   Config
+	any      bool
+	exact    map[string]bool
+	wildcard [][2]string
+	patterns []*regexp.Regexp
+}
+
+// New compiles config into a policy. It panics on an invalid origin pattern,
+// like regexp.MustCompile, and on the "*" origin with AllowCredentials, since
+// policies are built at startup.
+func New(config Config) *Policy {
+	policy := &Policy{config: config, exact: map[string]bool{}}
+	for _, origin := range config.AllowOrigins {
+		switch {
+		case origin == "*":
+			policy.any = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(strings.ToLower(origin), "*")
+			policy.wildcard = append(policy.wildcard, [2]string{prefix, suffix})
+		default:
+			policy.exact[strings.ToLower(origin)] = true
+		}
+	}
+	for _, pattern := range config.AllowOriginPatterns {
+		policy.patterns = append(policy.patterns, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+	if policy.any && config.AllowCredentials {
+		panic(`cors: the "*" origin cannot be combined with AllowCredentials, list the allowed origins instead`)
+	}
+	return policy
+}
+
+// Override returns a middleware replacing the global CORS settings for the
+// routes it is applied to. Apply it to a whole controller with routix.Use.
+func Override(config Config) gin.HandlerFunc {
+	return metadata.Set(CONFIG, New(config))
+}
+
+// AllowsOrigin reports whether origin may access the resource.
+func (p *Policy) AllowsOrigin(origin string) bool {
+	if p.any {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if p.exact[lower] {
+		return true
+	}
+	for _, wildcard := range p.wildcard {
+		if len(lower) > len(wildcard[0])+len(wildcard[1]) && strings.HasPrefix(lower, wildcard[0]) && strings.HasSuffix(lower, wildcard[1]) {
+			return true
+		}
+	}
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+	return p.config.AllowOriginFunc != nil && p.config.AllowOriginFunc(origin)
+}
+
+// Apply adds the CORS headers of an actual (non-preflight) request.
+func (p *Policy) Apply(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		return
+	}
+	header := c.Writer.Header()
+	header.Add("Vary", "Origin")
+	if !p.AllowsOrigin(origin) {
+		return
+	}
+
+	p.allowOrigin(header, origin)
+	if len(p.config.ExposeHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(p.config.ExposeHeaders, ", "))
+	}
+}
+
+// Preflight answers an OPTIONS request. registeredMethods are the methods
+// routed for the requested path; they are listed in the Allow header and, unless
+// AllowMethods is set, in Access-Control-Allow-Methods.
+func (p *Policy) Preflight(c *gin.Context, registeredMethods []string) {
+	header := c.Writer.Header()
+	allow := append(append([]string(nil), registeredMethods...), http.MethodOptions)
+	header.Set("Allow", strings.Join(allow, ", "))
+
+	origin := c.GetHeader("Origin")
+	requestMethod := c.GetHeader("Access-Control-Request-Method")
+	if origin == "" || requestMethod == "" {
+		// A plain OPTIONS request rather than a CORS preflight
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	methods := registeredMethods
+	if len(p.config.AllowMethods) > 0 {
+		methods = p.config.AllowMethods
+	}
+	if !p.AllowsOrigin(origin) || !containsFold(methods, requestMethod) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	p.allowOrigin(header, origin)
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(p.config.AllowHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(p.config.AllowHeaders, ", "))
+	} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if p.config.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(p.config.MaxAge.Seconds())))
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// allowOrigin sets Access-Control-Allow-Origin, echoing the origin unless any
+// origin is allowed.
+func (p *Policy) allowOrigin(header http.Header, origin string) {
+	if p.any {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.config.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
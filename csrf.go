@@ -0,0 +1,55 @@
+package routix
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/csrf"
+	"github.com/l1ttps/routix/exception"
+)
+
+// csrfSkipped holds "METHOD path" of every registered route exempted with csrf.Skip.
+var csrfSkipped map[string]bool
+
+// csrfStreamed holds "METHOD path" of every registered route streaming its
+// multipart body, which checks the form token itself.
+var csrfStreamed map[string]bool
+
+// useCSRF applies the middleware issuing CSRF tokens and validating them on
+// unsafe methods. A failed validation is answered with a 403 exception.
+func useCSRF(module *csrf.Module) {
+	csrfSkipped = map[string]bool{}
+	csrfStreamed = map[string]bool{}
+	if module == nil {
+		return
+	}
+
+	// Filled by resolveCSRFSkips once the routes of this server are registered
+	skipped, streamed := csrfSkipped, csrfStreamed
+	Driver.Use(func(c *gin.Context) {
+		// Unmatched requests fall through to the 404 and 405 handlers
+		route := c.Request.Method + " " + c.FullPath()
+		if c.FullPath() == "" || skipped[route] {
+			return
+		}
+		protect := module.Protect
+		if streamed[route] {
+			protect = module.ProtectStream
+		}
+		if err := protect(c); err != nil {
+			appOf(c).errorResponder(c, exception.ForbiddenException(err.Error()))
+			c.Abort()
+		}
+	})
+}
+
+// resolveCSRFSkips records the registered routes whose metadata exempts them
+// from CSRF validation or defers it to the streaming of their body.
+func resolveCSRFSkips() {
+	for _, route := range registeredRoutes {
+		if skip, exists := route.Metadata[csrf.SKIP]; exists && skip.(bool) {
+			csrfSkipped[string(route.Method)+" "+route.Path] = true
+		}
+		if streamed, exists := route.Metadata[csrf.STREAMED]; exists && streamed.(bool) {
+			csrfStreamed[string(route.Method)+" "+route.Path] = true
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package csrf
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/logger"
+	"github.com/l1ttps/routix/metadata"
+	"github.com/l1ttps/routix/view"
+)
+
+const (
+	// TOKEN is the context key holding the CSRF token of the request, issued
+	// when it is first read with Token or by a template.
+	TOKEN string = "ROUTIX_CSRF_TOKEN"
+	// SKIP is the metadata key set by Skip on routes exempt from CSRF validation.
+	SKIP string = "ROUTIX_CSRF_SKIP"
+	// STREAMED is the metadata key set on routes streaming multipart bodies,
+	// such as upload routes, which check the form token themselves with Deferred.
+	STREAMED string = "ROUTIX_CSRF_STREAMED"
+	// PENDING is the context key holding the Pending validation of a streamed multipart body.
+	PENDING string = "ROUTIX_CSRF_PENDING"
+)
+
+// Errors returned by Protect when validation fails.
+var (
+	ErrMissingToken = errors.New("missing CSRF token")
+	ErrInvalidToken = errors.New("invalid CSRF token")
+)
+
+// Mode selects where the expected token is kept.
+type Mode int
+
+const (
+	// DoubleSubmit keeps a signed token in a cookie; the submitted token must match it.
+	DoubleSubmit Mode = iota
+	// Synchronizer keeps the token on the server, in Config.Store.
+	Synchronizer
+)
+
+// Store keeps the synchronizer token of a client.
+type Store interface {
+	// Load returns the token saved for the client of c, or "" when there is none.
+	Load(c *gin.Context) (string, error)
+	// Save saves token for the client of c.
+	Save(c *gin.Context, token string) error
+}
+
+// Config holds the settings of the CSRF module. Empty settings use the
+// defaults documented on each field.
+type Config struct {
+	// Mode defaults to DoubleSubmit.
+	Mode Mode
+	// Secret signs double-submit tokens. Defaults to a random secret, which
+	// invalidates issued tokens on restart and cannot be shared by replicas.
+	Secret []byte
+	// Store keeps synchronizer tokens. Defaults to an in-memory store
+	// identifying clients with a cookie.
+	Store Store
+	// MaxStoredTokens is the number of clients the default synchronizer store
+	// keeps tokens for; the oldest tokens are dropped beyond. Defaults to 100000.
+	MaxStoredTokens int
+	// CookieName is the name of the token cookie (DoubleSubmit) or of the client
+	// id cookie of the default store (Synchronizer). Defaults to "_csrf".
+	CookieName string
+	// CookiePath defaults to "/".
+	CookiePath   string
+	CookieDomain string
+	Secure       bool
+	// SameSite defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// MaxAge is the lifetime of tokens. Defaults to 12 hours.
+	MaxAge time.Duration
+	// HeaderName is the request header carrying the token. Defaults to "X-CSRF-Token".
+	HeaderName string
+	// FieldName is the form field carrying the token. Defaults to "_csrf".
+	FieldName string
+}
+
+// Module issues CSRF tokens and validates them on unsafe methods.
+type Module struct {
+	config Config
+}
+
+// New creates a CSRF module. Mount it with ServerConfig.CSRF.
+func New(config Config) *Module {
+	if config.CookieName == "" {
+		config.CookieName = "_csrf"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 12 * time.Hour
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+	if config.FieldName == "" {
+		config.FieldName = "_csrf"
+	}
+	if config.MaxStoredTokens <= 0 {
+		config.MaxStoredTokens = 100000
+	}
+	if len(config.Secret) == 0 {
+		config.Secret = randomBytes(32)
+	}
+	module := &Module{config: config}
+	if config.Mode == Synchronizer && config.Store == nil {
+		module.config.Store = newMemoryStore(module)
+	}
+	return module
+}
+
+// Skip returns a middleware exempting the routes it is applied to from CSRF
+// validation, such as JSON APIs authenticated with bearer tokens. Apply it to
+// a whole controller with routix.Use.
+func Skip() gin.HandlerFunc {
+	return metadata.Set(SKIP, true)
+}
+
+// Token returns the CSRF token of the request, issuing one when the client has
+// none. It is empty when the route skips CSRF or the token cannot be issued.
+func Token(c *gin.Context) string {
+	value, _ := c.Get(TOKEN)
+	if token, ok := value.(*lazyToken); ok {
+		return token.get()
+	}
+	return ""
+}
+
+// Protect checks, for unsafe methods, the token submitted in the header or
+// form field against the client's token.
+//
+// The token is exposed to templates as csrfToken, and as csrfField, a hidden
+// input ready to be placed in forms. Clients get a token, and its cookie, only
+// when a template or Token reads it, so that requests rendering no form leave
+// no state behind.
+func (m *Module) Protect(c *gin.Context) error {
+	return m.protect(c, false)
+}
+
+// ProtectStream is Protect for routes streaming their multipart bodies. When a
+// multipart request has no token header, the form field is not parsed, as it
+// would buffer the whole body: the validation is deferred to the handler
+// streaming the body, with Deferred.
+func (m *Module) ProtectStream(c *gin.Context) error {
+	return m.protect(c, true)
+}
+
+func (m *Module) protect(c *gin.Context, streamed bool) error {
+	lazy := &lazyToken{module: m, ctx: c}
+	c.Set(TOKEN, lazy)
+	view.Set(c, "csrfToken", view.Lazy(func() any { return lazy.get() }))
+	view.Set(c, "csrfField", view.Lazy(func() any {
+		return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(m.config.FieldName) + `" value="` + template.HTMLEscapeString(lazy.get()) + `">`)
+	}))
+
+	if isSafeMethod(c.Request.Method) {
+		return nil
+	}
+	token, err := m.current(c)
+	if err != nil {
+		return err
+	}
+	// Templates rendered for this request read the checked token
+	if token != "" {
+		lazy.token, lazy.issued = token, true
+	}
+	submitted := c.GetHeader(m.config.HeaderName)
+	if submitted == "" && streamed && c.ContentType() == "multipart/form-data" {
+		c.Set(PENDING, &Pending{Field: m.config.FieldName, token: token})
+		return nil
+	}
+	if submitted == "" {
+		submitted = c.PostForm(m.config.FieldName)
+	}
+	if submitted == "" {
+		return ErrMissingToken
+	}
+	if subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// Pending is the deferred validation of the form token of a streamed multipart body.
+type Pending struct {
+	// Field is the form field carrying the token.
+	Field string
+	token string
+}
+
+// Check validates the submitted token.
+func (p *Pending) Check(submitted string) error {
+	if submitted == "" {
+		return ErrMissingToken
+	}
+	if subtle.ConstantTimeCompare([]byte(submitted), []byte(p.token)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// Deferred returns the pending validation of the request, set by ProtectStream.
+// The handler streaming the body must Check the token of the Field part before
+// acting on the request, and reject the request when the body has none.
+func Deferred(c *gin.Context) (*Pending, bool) {
+	value, _ := c.Get(PENDING)
+	pending, deferred := value.(*Pending)
+	return pending, deferred
+}
+
+// lazyToken issues the token of a request when it is first read.
+type lazyToken struct {
+	module *Module
+	ctx    *gin.Context
+	token  string
+	issued bool
+}
+
+func (t *lazyToken) get() string {
+	if !t.issued {
+		token, err := t.module.issue(t.ctx)
+		if err != nil {
+			logger.Logger("CSRF").Error(fmt.Sprintf("Cannot issue CSRF token: %s", err))
+			return ""
+		}
+		t.token, t.issued = token, true
+	}
+	return t.token
+}
+
+// current returns the client's current token, empty when it has none.
+func (m *Module) current(c *gin.Context) (string, error) {
+	if m.config.Mode == Synchronizer {
+		return m.config.Store.Load(c)
+	}
+	if cookie, err := c.Cookie(m.config.CookieName); err == nil && m.validSignature(cookie) {
+		return cookie, nil
+	}
+	return "", nil
+}
+
+// issue returns the client's current token, creating one when it has none.
+func (m *Module) issue(c *gin.Context) (string, error) {
+	token, err := m.current(c)
+	if err != nil || token != "" {
+		return token, err
+	}
+	if m.config.Mode == Synchronizer {
+		token = encode(randomBytes(32))
+		return token, m.config.Store.Save(c, token)
+	}
+	random := encode(randomBytes(32))
+	token = random + "." + m.sign(random)
+	m.setCookie(c, token)
+	return token, nil
+}
+
+func (m *Module) sign(value string) string {
+	mac := hmac.New(sha256.New, m.config.Secret)
+	mac.Write([]byte(value))
+	return encode(mac.Sum(nil))
+}
+
+func (m *Module) validSignature(token string) bool {
+	random, signature, found := strings.Cut(token, ".")
+	return found && hmac.Equal([]byte(signature), []byte(m.sign(random)))
+}
+
+func (m *Module) setCookie(c *gin.Context, value string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		MaxAge:   int(m.config.MaxAge.Seconds()),
+		Secure:   m.config.Secure,
+		HttpOnly: true,
+		SameSite: m.config.SameSite,
+	})
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func randomBytes(size int) []byte {
+	value := make([]byte, size)
+	if _, err := rand.Read(value); err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func encode(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
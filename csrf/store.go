@@ -0,0 +1,68 @@
+package csrf
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore is the default synchronizer store. It identifies clients with a
+// random id in the module's cookie and keeps their tokens in memory, up to
+// Config.MaxStoredTokens of them.
+type memoryStore struct {
+	module *Module
+	mu     sync.Mutex
+	tokens map[string]*list.Element
+	// order lists the tokens from the oldest, which is also the first to expire
+	order *list.List
+}
+
+type memoryToken struct {
+	id      string
+	value   string
+	expires time.Time
+}
+
+func newMemoryStore(module *Module) *memoryStore {
+	return &memoryStore{module: module, tokens: map[string]*list.Element{}, order: list.New()}
+}
+
+func (s *memoryStore) Load(c *gin.Context) (string, error) {
+	id, err := c.Cookie(s.module.config.CookieName)
+	if err != nil {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, exists := s.tokens[id]
+	if !exists {
+		return "", nil
+	}
+	token := element.Value.(memoryToken)
+	if time.Now().After(token.expires) {
+		return "", nil
+	}
+	return token.value, nil
+}
+
+func (s *memoryStore) Save(c *gin.Context, token string) error {
+	id := encode(randomBytes(32))
+	s.module.setCookie(c, id)
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Drop the expired tokens, then the oldest ones beyond the limit
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		oldest := front.Value.(memoryToken)
+		if now.Before(oldest.expires) && s.order.Len() < s.module.config.MaxStoredTokens {
+			break
+		}
+		s.order.Remove(front)
+		delete(s.tokens, oldest.id)
+	}
+	s.tokens[id] = s.order.PushBack(memoryToken{id: id, value: token, expires: now.Add(s.module.config.MaxAge)})
+	return nil
+}
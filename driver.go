@@ -1,6 +1,10 @@
 package routix
 
-import "github.com/gin-gonic/gin"
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
 
 // Driver is the global instance of the Gin engine
 var Driver *gin.Engine
@@ -14,6 +18,9 @@ var BaseViewDir string = "views/*"
 // IsEnableRender determines whether rendering is enabled or not
 var IsEnableRender bool = false
 
+// ViewReloadInterval is how often views are checked for changes in debug mode
+var ViewReloadInterval time.Duration = time.Second
+
 type ControllerType func()
 
 type MiddlewareType gin.HandlerFunc
@@ -2,11 +2,52 @@ package exception
 
 import "net/http"
 
+// EXCEPTION is the context key holding the exception a request was answered with.
+const EXCEPTION string = "ROUTIX_EXCEPTION"
+
 type HttpExceptionResponse struct {
 	Status  int
 	Message string
 }
 
+// Error returns the exception message so an HttpExceptionResponse can be used as an error.
+func (e HttpExceptionResponse) Error() string {
+	return e.Message
+}
+
+// names maps the status codes of the built-in constructors to their exception names.
+var names = map[int]string{
+	400: "BadRequestException",
+	401: "UnauthorizedException",
+	403: "ForbiddenException",
+	404: "NotFoundException",
+	405: "MethodNotAllowedException",
+	406: "NotAcceptableException",
+	408: "RequestTimeoutException",
+	409: "ConflictException",
+	410: "GoneException",
+	412: "PreconditionFailedException",
+	413: "PayloadTooLargeException",
+	415: "UnsupportedMediaTypeException",
+	418: "ImATeapotException",
+	422: "UnprocessableEntityException",
+	500: "InternalServerErrorException",
+	501: "NotImplementedException",
+	502: "BadGatewayException",
+	503: "ServiceUnavailableException",
+	504: "GatewayTimeoutException",
+	505: "HttpVersionNotSupportedException",
+}
+
+// Name returns the name of the exception type, such as "NotFoundException", or
+// "HttpException" for statuses without a dedicated constructor.
+func (e HttpExceptionResponse) Name() string {
+	if name, exists := names[e.Status]; exists {
+		return name
+	}
+	return "HttpException"
+}
+
 // HttpException creates a new HttpExceptionResponse with the given status and message.
 //
 // The status parameter specifies the HTTP status code for the exception.
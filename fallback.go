@@ -0,0 +1,108 @@
+package routix
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/static"
+)
+
+// fallback answers requests matching no route through PipeResponse, so the
+// exceptions of notFound and methodNotAllowed are written by the error
+// responder like the ones of any route. Nil handlers return the 404 and 405 exceptions.
+func fallback(notFound func(c *gin.Context) interface{}, methodNotAllowed func(c *gin.Context) interface{}) {
+	if notFound == nil {
+		notFound = func(c *gin.Context) interface{} {
+			return exception.NotFoundException()
+		}
+	}
+	if methodNotAllowed == nil {
+		methodNotAllowed = func(c *gin.Context) interface{} {
+			return exception.MethodNotAllowedException()
+		}
+	}
+
+	// Answer 405 instead of 404 when the path is routed for other methods
+	Driver.HandleMethodNotAllowed = true
+
+	// Fallback method not allowed
+	methodNotAllowedHandler := PipeResponse(methodNotAllowed)
+	Driver.NoMethod(func(c *gin.Context) {
+		c.Header("Allow", strings.Join(allowedMethods(appOf(c).engine, c.Request.URL.Path), ", "))
+		methodNotAllowedHandler(c)
+	})
+
+	// Fallback router not found
+	notFoundHandler := PipeResponse(notFound)
+	Driver.NoRoute(func(c *gin.Context) {
+		if serveStatic(c) {
+			return
+		}
+		notFoundHandler(c)
+	})
+}
+
+// allowedMethods returns the methods routed for requestPath on engine.
+func allowedMethods(engine *gin.Engine, requestPath string) []string {
+	methods := []string{}
+	seen := map[string]bool{}
+	for _, route := range engine.Routes() {
+		if !seen[route.Method] && matchRoutePath(route.Path, requestPath) {
+			seen[route.Method] = true
+			methods = append(methods, route.Method)
+		}
+	}
+	return methods
+}
+
+// matchRoutePath reports whether requestPath matches a gin route template
+// with :param and *catchAll segments.
+func matchRoutePath(template string, requestPath string) bool {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(requestPath, "/"), "/")
+	for index, segment := range templateSegments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if index >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[index] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[index] {
+			return false
+		}
+	}
+	return len(templateSegments) == len(pathSegments)
+}
+
+// serveStatic serves the file matching an unrouted GET or HEAD request from the
+// static modules, then tries their SPA fallbacks unless the path is under PathRoot.
+func serveStatic(c *gin.Context) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	state := appOf(c)
+	for _, module := range state.staticModules {
+		if module.Serve(c) {
+			return true
+		}
+	}
+
+	// Unknown API paths keep their 404
+	if state.pathRoot != "/" && static.HasPathPrefix(c.Request.URL.Path, state.pathRoot) {
+		return false
+	}
+	for _, module := range state.staticModules {
+		if module.Fallback(c) {
+			return true
+		}
+	}
+	return false
+}
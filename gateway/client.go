@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/l1ttps/routix/exception"
+)
+
+// ErrClientClosed is returned when emitting to a client whose connection is closed.
+var ErrClientClosed = errors.New("gateway: client closed")
+
+// ErrSendBufferFull is returned when a client does not read fast enough to keep
+// up with its messages. The client is disconnected unless Config.KeepSlowClients is set.
+var ErrSendBufferFull = errors.New("gateway: client send buffer full")
+
+// Client is a connected WebSocket client.
+type Client struct {
+	// ID identifies the client across instances.
+	ID string
+	// Context is the context of the upgrade request. It stays valid until the client disconnects.
+	Context *gin.Context
+
+	gateway *Gateway
+	conn    *websocket.Conn
+	send    chan []byte
+	done    chan struct{}
+
+	mu        sync.RWMutex
+	rooms     map[string]struct{}
+	closeOnce sync.Once
+}
+
+func newClient(gateway *Gateway, conn *websocket.Conn, c *gin.Context) *Client {
+	return &Client{
+		ID:      newClientID(),
+		Context: c,
+		gateway: gateway,
+		conn:    conn,
+		send:    make(chan []byte, gateway.config.SendBuffer),
+		done:    make(chan struct{}),
+		rooms:   map[string]struct{}{},
+	}
+}
+
+// Emit sends an event to the client.
+func (c *Client) Emit(event string, data any) error {
+	payload, err := encodeMessage(event, data)
+	if err != nil {
+		return err
+	}
+	return c.write(payload)
+}
+
+// Broadcast sends an event to every client of the gateway except this one.
+func (c *Client) Broadcast(event string, data any) error {
+	return c.gateway.hub.publish("", c.ID, event, data)
+}
+
+// BroadcastTo sends an event to every client in room except this one.
+func (c *Client) BroadcastTo(room string, event string, data any) error {
+	return c.gateway.hub.publish(room, c.ID, event, data)
+}
+
+// Join adds the client to room.
+func (c *Client) Join(room string) {
+	c.mu.Lock()
+	c.rooms[room] = struct{}{}
+	c.mu.Unlock()
+	c.gateway.hub.join(c, room)
+}
+
+// Leave removes the client from room.
+func (c *Client) Leave(room string) {
+	c.mu.Lock()
+	delete(c.rooms, room)
+	c.mu.Unlock()
+	c.gateway.hub.leave(c, room)
+}
+
+// Rooms returns the rooms the client has joined.
+func (c *Client) Rooms() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Close closes the connection after sending the messages already queued.
+// OnDisconnect runs once the connection is closed.
+func (c *Client) Close() {
+	c.close()
+}
+
+// close signals the write loop to flush pending messages and close the connection.
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *Client) write(payload []byte) error {
+	select {
+	case <-c.done:
+		return ErrClientClosed
+	default:
+	}
+
+	select {
+	case c.send <- payload:
+		return nil
+	case <-c.done:
+		return ErrClientClosed
+	default:
+		if !c.gateway.config.KeepSlowClients {
+			// Queued messages cannot be flushed to a client this slow
+			c.close()
+			c.conn.Close()
+		}
+		return ErrSendBufferFull
+	}
+}
+
+func (c *Client) emitException(err error) {
+	httpException, ok := err.(exception.HttpExceptionResponse)
+	if !ok {
+		httpException = exception.InternalServerErrorException(err.Error())
+	}
+	c.Emit(EXCEPTION, gin.H{
+		"status":  httpException.Status,
+		"message": httpException.Message,
+	})
+}
+
+// readPump reads messages until the connection fails or is closed.
+func (c *Client) readPump() {
+	config := c.gateway.config
+	c.conn.SetReadLimit(config.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	})
+
+	for {
+		_, payload, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+
+		var message Message
+		if err := json.Unmarshal(payload, &message); err != nil || message.Event == "" {
+			c.emitException(exception.BadRequestException("Invalid message"))
+			continue
+		}
+		c.gateway.dispatch(c, message)
+	}
+}
+
+// writePump serializes writes to the connection and keeps it alive with pings.
+func (c *Client) writePump() {
+	config := c.gateway.config
+	ticker := time.NewTicker(config.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.close()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			c.flush()
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(config.WriteWait))
+			return
+		case payload := <-c.send:
+			if err := c.writeMessage(payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(config.WriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// flush writes the messages still queued when the client is closed.
+func (c *Client) flush() {
+	for {
+		select {
+		case payload := <-c.send:
+			if err := c.writeMessage(payload); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) writeMessage(payload []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.gateway.config.WriteWait))
+	return c.conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+func encodeMessage(event string, data any) ([]byte, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Message{Event: event, Data: encoded})
+}
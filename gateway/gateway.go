@@ -0,0 +1,190 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+	"github.com/l1ttps/routix/exception"
+)
+
+// EXCEPTION is the event used to send exceptions returned by handlers to the client.
+const EXCEPTION string = "exception"
+
+// Message is the envelope exchanged with clients: {"event": "...", "data": ...}.
+type Message struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Bind decodes the message data into obj and validates it like a request body.
+//
+// It returns a BadRequestException when decoding or validation fails, so
+// handlers can return the error as-is.
+func (m Message) Bind(obj any) error {
+	if err := json.Unmarshal(m.Data, obj); err != nil {
+		return exception.BadRequestException(err.Error())
+	}
+	if binding.Validator != nil {
+		if err := binding.Validator.ValidateStruct(obj); err != nil {
+			return exception.BadRequestException(err.Error())
+		}
+	}
+	return nil
+}
+
+// MessageHandler handles one event type. Its return value is sent back to the
+// client under the same event; an HttpExceptionResponse is sent as an
+// "exception" event and nil sends nothing.
+type MessageHandler func(client *Client, message Message) any
+
+// Config holds the connection settings of a gateway.
+type Config struct {
+	// PingInterval is the interval between pings sent to the client. Defaults to 30 seconds.
+	PingInterval time.Duration
+	// PongWait is how long to wait for a pong (or any message) before closing. Defaults to 60 seconds.
+	PongWait time.Duration
+	// WriteWait is the deadline for a single write. Defaults to 10 seconds.
+	WriteWait time.Duration
+	// MaxMessageSize is the largest message accepted from a client, in bytes. Defaults to 64KB.
+	MaxMessageSize int64
+	// SendBuffer is the number of outgoing messages queued per client. Defaults to 256.
+	SendBuffer int
+	// KeepSlowClients keeps the clients whose send buffer is full connected,
+	// dropping the messages they cannot keep up with. By default they are
+	// disconnected, so that they reconnect rather than miss messages unnoticed.
+	KeepSlowClients bool
+	// CheckOrigin validates the Origin of the upgrade request. Defaults to same-origin only.
+	CheckOrigin func(r *http.Request) bool
+	// Adapter fans broadcasts out across instances. Defaults to an in-process adapter.
+	Adapter Adapter
+}
+
+// Gateway routes WebSocket messages to handlers by event type.
+type Gateway struct {
+	// Path is the path of the upgrade route, relative to the server's PathRoot.
+	Path string
+	// Middlewares run on the upgrade request, so guards and interceptors work as on routes.
+	Middlewares []gin.HandlerFunc
+
+	config       Config
+	hub          *Hub
+	upgrader     websocket.Upgrader
+	handlers     map[string]MessageHandler
+	onConnect    func(client *Client) error
+	onDisconnect func(client *Client)
+}
+
+// New creates a gateway upgraded on path, with middlewares such as guard.UseGuard
+// running on the upgrade request. Mount it with ServerConfig.Gateways.
+func New(path string, middlewares ...gin.HandlerFunc) *Gateway {
+	return NewWithConfig(path, Config{}, middlewares...)
+}
+
+// NewWithConfig creates a gateway like New with custom connection settings.
+func NewWithConfig(path string, config Config, middlewares ...gin.HandlerFunc) *Gateway {
+	if config.PingInterval == 0 {
+		config.PingInterval = 30 * time.Second
+	}
+	if config.PongWait == 0 {
+		config.PongWait = 60 * time.Second
+	}
+	if config.WriteWait == 0 {
+		config.WriteWait = 10 * time.Second
+	}
+	if config.MaxMessageSize == 0 {
+		config.MaxMessageSize = 64 << 10
+	}
+	if config.SendBuffer == 0 {
+		config.SendBuffer = 256
+	}
+	if config.Adapter == nil {
+		config.Adapter = NewMemoryAdapter()
+	}
+
+	return &Gateway{
+		Path:        path,
+		Middlewares: middlewares,
+		config:      config,
+		hub:         NewHub(config.Adapter),
+		upgrader:    websocket.Upgrader{CheckOrigin: config.CheckOrigin},
+		handlers:    map[string]MessageHandler{},
+	}
+}
+
+// On registers the handler for an event type.
+func (g *Gateway) On(event string, handler MessageHandler) *Gateway {
+	g.handlers[event] = handler
+	return g
+}
+
+// OnConnect registers a function called after a client connects. Returning an
+// error sends it as an exception and closes the connection.
+func (g *Gateway) OnConnect(fn func(client *Client) error) *Gateway {
+	g.onConnect = fn
+	return g
+}
+
+// OnDisconnect registers a function called after a client disconnects.
+func (g *Gateway) OnDisconnect(fn func(client *Client)) *Gateway {
+	g.onDisconnect = fn
+	return g
+}
+
+// Hub returns the hub of the gateway, used to broadcast from anywhere in the application.
+func (g *Gateway) Hub() *Hub {
+	return g.hub
+}
+
+// Handle upgrades the request and serves the connection until it closes.
+func (g *Gateway) Handle(c *gin.Context) {
+	conn, err := g.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error response
+		c.Abort()
+		return
+	}
+
+	client := newClient(g, conn, c)
+	g.hub.add(client)
+	go client.writePump()
+
+	defer func() {
+		g.hub.remove(client)
+		client.close()
+		if g.onDisconnect != nil {
+			g.onDisconnect(client)
+		}
+	}()
+
+	if g.onConnect != nil {
+		if err := g.onConnect(client); err != nil {
+			client.emitException(err)
+			return
+		}
+	}
+
+	client.readPump()
+}
+
+// dispatch runs the handler of message and sends back its result.
+func (g *Gateway) dispatch(client *Client, message Message) {
+	handler, exists := g.handlers[message.Event]
+	if !exists {
+		client.emitException(exception.NotFoundException("Unknown event: " + message.Event))
+		return
+	}
+
+	response := handler(client, message)
+	switch response := response.(type) {
+	case nil:
+		return
+	case exception.HttpExceptionResponse:
+		client.emitException(response)
+	default:
+		client.Emit(message.Event, response)
+	}
+}
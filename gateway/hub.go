@@ -0,0 +1,185 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Envelope is a broadcast travelling through an Adapter.
+type Envelope struct {
+	// Room is the target room, or empty for every client of the gateway.
+	Room string `json:"room,omitempty"`
+	// Except is the ID of a client that must not receive the message, usually its sender.
+	Except string `json:"except,omitempty"`
+	// Payload is the encoded message.
+	Payload []byte `json:"payload"`
+}
+
+// Adapter fans broadcasts out to every instance of the application.
+//
+// The in-process MemoryAdapter only reaches clients connected to the current
+// instance. A pub/sub adapter (Redis, NATS, ...) publishes envelopes to a
+// shared channel and calls the deliver function of every instance with the
+// envelopes it receives, including its own.
+type Adapter interface {
+	// Publish sends an envelope to every instance.
+	Publish(envelope Envelope) error
+	// Subscribe registers the function delivering envelopes to local clients.
+	Subscribe(deliver func(envelope Envelope))
+}
+
+// MemoryAdapter delivers broadcasts to the current instance only.
+type MemoryAdapter struct {
+	mu       sync.RWMutex
+	delivers []func(envelope Envelope)
+}
+
+// NewMemoryAdapter creates an in-process adapter.
+func NewMemoryAdapter() *MemoryAdapter {
+	return &MemoryAdapter{}
+}
+
+// Publish delivers the envelope to every subscribed hub.
+func (a *MemoryAdapter) Publish(envelope Envelope) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, deliver := range a.delivers {
+		deliver(envelope)
+	}
+	return nil
+}
+
+// Subscribe registers a hub's deliver function.
+func (a *MemoryAdapter) Subscribe(deliver func(envelope Envelope)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.delivers = append(a.delivers, deliver)
+}
+
+// Hub tracks the clients of a gateway and the rooms they joined.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+	rooms   map[string]map[string]*Client
+	adapter Adapter
+}
+
+// NewHub creates a hub broadcasting through adapter.
+func NewHub(adapter Adapter) *Hub {
+	hub := &Hub{
+		clients: map[string]*Client{},
+		rooms:   map[string]map[string]*Client{},
+		adapter: adapter,
+	}
+	adapter.Subscribe(hub.deliver)
+	return hub
+}
+
+// Broadcast sends an event to every client of the gateway, on every instance.
+func (h *Hub) Broadcast(event string, data any) error {
+	return h.publish("", "", event, data)
+}
+
+// BroadcastTo sends an event to every client in room, on every instance.
+func (h *Hub) BroadcastTo(room string, event string, data any) error {
+	return h.publish(room, "", event, data)
+}
+
+// Client returns the client with the given ID when it is connected to this instance.
+func (h *Hub) Client(id string) (*Client, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	client, exists := h.clients[id]
+	return client, exists
+}
+
+// Len returns the number of clients connected to this instance.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+func (h *Hub) publish(room string, except string, event string, data any) error {
+	payload, err := encodeMessage(event, data)
+	if err != nil {
+		return err
+	}
+	return h.adapter.Publish(Envelope{Room: room, Except: except, Payload: payload})
+}
+
+// deliver writes an envelope to the matching local clients.
+func (h *Hub) deliver(envelope Envelope) {
+	h.mu.RLock()
+	targets := h.clients
+	if envelope.Room != "" {
+		targets = h.rooms[envelope.Room]
+	}
+	clients := make([]*Client, 0, len(targets))
+	for id, client := range targets {
+		if id != envelope.Except {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.write(envelope.Payload)
+	}
+}
+
+func (h *Hub) add(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[client.ID] = client
+}
+
+func (h *Hub) remove(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, client.ID)
+	for _, room := range client.Rooms() {
+		h.removeFromRoom(client, room)
+	}
+}
+
+func (h *Hub) join(client *Client, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// A client joining while it disconnects must not be left in the room
+	if _, connected := h.clients[client.ID]; !connected {
+		return
+	}
+	select {
+	case <-client.done:
+		return
+	default:
+	}
+	members, exists := h.rooms[room]
+	if !exists {
+		members = map[string]*Client{}
+		h.rooms[room] = members
+	}
+	members[client.ID] = client
+}
+
+func (h *Hub) leave(client *Client, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeFromRoom(client, room)
+}
+
+func (h *Hub) removeFromRoom(client *Client, room string) {
+	members := h.rooms[room]
+	delete(members, client.ID)
+	if len(members) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+func newClientID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,55 @@
+package guard
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/internal/funcname"
+)
+
+// OVERRIDES is the context key holding the guard replacements of the request.
+const OVERRIDES string = "ROUTIX_GUARD_OVERRIDES"
+
+type overrides map[string]func(c *gin.Context) bool
+
+// Override returns a middleware that makes every UseGuard of the request call
+// replacement instead of original. A nil replacement skips original, as if it
+// allowed the request.
+//
+// Guards are matched by identity, so overriding guards.JwtGuard affects every
+// route using it, and overriding guards.Roles("") affects every closure
+// returned by guards.Roles. Function literals, such as the value of a
+// package-level var AdminGuard = func(...) bool, are matched by themselves
+// only. Override panics when original cannot be identified. Register the middleware before
+// the routes run, with ServerConfig.Middlewares or the routixtest options:
+//
+//	guard.Override(guards.JwtGuard, func(c *gin.Context) bool {
+//		c.Set("user", testUser)
+//		return true
+//	})
+func Override(original func(c *gin.Context) bool, replacement func(c *gin.Context) bool) gin.HandlerFunc {
+	id, _ := funcname.Identity(original)
+	if id == "" {
+		panic("guard: cannot identify the overridden function")
+	}
+	return func(c *gin.Context) {
+		value, _ := c.Get(OVERRIDES)
+		previous, _ := value.(overrides)
+		current := make(overrides, len(previous)+1)
+		for key, value := range previous {
+			current[key] = value
+		}
+		current[id] = replacement
+		c.Set(OVERRIDES, current)
+	}
+}
+
+// resolve returns the function to run in place of authFunc, identified by id,
+// for the request, and false when it is disabled.
+func resolve(c *gin.Context, id string, authFunc func(c *gin.Context) bool) (func(c *gin.Context) bool, bool) {
+	value, _ := c.Get(OVERRIDES)
+	table, _ := value.(overrides)
+	replacement, overridden := table[id]
+	if !overridden {
+		return authFunc, true
+	}
+	return replacement, replacement != nil
+}
@@ -4,8 +4,23 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/internal/funcname"
+	"github.com/l1ttps/routix/metadata"
+	"github.com/l1ttps/routix/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// REJECTED is the context key set to true when a guard rejects the request.
+const REJECTED string = "ROUTIX_GUARD_REJECTED"
+
+// GUARDS is the metadata key listing the names of the guard functions of a
+// route, such as "guards.JwtGuard", in the order they run. Closures are listed
+// under the function that created them, such as "guards.Roles", and
+// function literals of package-level variables under their generated name,
+// such as "guards.init.func1".
+const GUARDS string = "ROUTIX_GUARDS"
+
 // UseGuard is a function that takes in one or more authentication functions and returns a Gin middleware handler.
 //
 // The authentication functions are passed in as variadic arguments, represented by the `authFuncs` parameter. These functions take in a Gin context (`c *gin.Context`) and return a boolean value indicating whether the authentication is successful or not.
@@ -14,11 +29,30 @@ import (
 //
 // If all authentication functions return `true`, indicating that the authentication is successful, the handler calls the `Next()` method on the Gin context to pass the request to the next middleware or route handler in the chain.
 //
-// UseGuard does not have any return values.
+// Each authentication function may be replaced or skipped per request by Override,
+// which is how tests swap guards without changing controllers.
 func UseGuard(authFuncs ...func(c *gin.Context) bool) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		for _, authFunc := range authFuncs {
-			if !authFunc(c) {
+	names := make([]string, len(authFuncs))
+	ids := make([]string, len(authFuncs))
+	for i, authFunc := range authFuncs {
+		ids[i], names[i] = funcname.Identity(authFunc)
+	}
+
+	return metadata.Attach(func(c *gin.Context) {
+		for i, authFunc := range authFuncs {
+			authFunc, active := resolve(c, ids[i], authFunc)
+			if !active {
+				continue
+			}
+			allowed := false
+			tracing.Span(c, "guard "+names[i], func(span trace.Span) {
+				allowed = authFunc(c)
+				if !allowed {
+					span.SetStatus(codes.Error, "rejected")
+				}
+			})
+			if !allowed {
+				c.Set(REJECTED, true)
 				statusCode := http.StatusForbidden
 				c.JSON(statusCode, gin.H{
 					"status":  statusCode,
@@ -29,5 +63,5 @@ func UseGuard(authFuncs ...func(c *gin.Context) bool) gin.HandlerFunc {
 			}
 		}
 		c.Next()
-	}
+	}, GUARDS, names)
 }
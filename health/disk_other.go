@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "errors"
+
+// diskUsage is not supported outside unix systems.
+func diskUsage(path string) (free uint64, total uint64, err error) {
+	return 0, 0, errors.New("disk indicator is not supported on this platform")
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status values used in health reports.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+	StatusUp    = "up"
+	StatusDown  = "down"
+)
+
+// Indicator checks one dependency or resource of the application.
+type Indicator interface {
+	// Name identifies the indicator in the report.
+	Name() string
+	// Check returns details to include in the report, or an error when the
+	// indicator is down. It must return once ctx is done.
+	Check(ctx context.Context) (map[string]any, error)
+}
+
+// Config holds the settings of the health module.
+type Config struct {
+	// Path is the path the endpoints are mounted under, relative to the server
+	// root rather than PathRoot. Defaults to "/health".
+	Path string
+	// Liveness indicators are checked by GET {Path}/live.
+	Liveness []Indicator
+	// Readiness indicators are checked by GET {Path}/ready.
+	Readiness []Indicator
+	// Timeout bounds every single check. Defaults to 3 seconds.
+	Timeout time.Duration
+}
+
+// Module serves liveness and readiness endpoints.
+type Module struct {
+	config       Config
+	shuttingDown atomic.Bool
+}
+
+// Report is the JSON body returned by the health endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is the outcome of one indicator.
+type CheckResult struct {
+	Status  string         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// New creates a health module. Mount it with ServerConfig.Health.
+func New(config Config) *Module {
+	if config.Path == "" {
+		config.Path = "/health"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 3 * time.Second
+	}
+	return &Module{config: config}
+}
+
+// Path returns the path the endpoints are mounted under.
+func (m *Module) Path() string {
+	return m.config.Path
+}
+
+// Live is the handler of the liveness endpoint.
+func (m *Module) Live(c *gin.Context) {
+	m.respond(c, m.Check(c.Request.Context(), m.config.Liveness))
+}
+
+// Ready is the handler of the readiness endpoint. It reports 503 once the
+// server is shutting down, whatever the indicators say.
+func (m *Module) Ready(c *gin.Context) {
+	report := m.Check(c.Request.Context(), m.config.Readiness)
+	if m.shuttingDown.Load() {
+		report.Status = StatusError
+		report.Checks["shutdown"] = CheckResult{Status: StatusDown, Error: "server is shutting down"}
+	}
+	m.respond(c, report)
+}
+
+// SetShuttingDown marks the server as shutting down, which makes readiness fail.
+// routix calls it when a graceful shutdown starts.
+func (m *Module) SetShuttingDown() {
+	m.shuttingDown.Store(true)
+}
+
+// Check runs indicators concurrently, each bounded by the configured timeout,
+// and aggregates their results.
+func (m *Module) Check(ctx context.Context, indicators []Indicator) Report {
+	report := Report{Status: StatusOK, Checks: map[string]CheckResult{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, indicator := range indicators {
+		wg.Add(1)
+		go func(indicator Indicator) {
+			defer wg.Done()
+			result := m.run(ctx, indicator)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[indicator.Name()] = result
+			if result.Status != StatusUp {
+				report.Status = StatusError
+			}
+		}(indicator)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run checks one indicator, reporting it down when it does not answer in time.
+func (m *Module) run(ctx context.Context, indicator Indicator) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]any
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := indicator.Check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return CheckResult{Status: StatusDown, Error: "timeout after " + m.config.Timeout.String()}
+	case result := <-done:
+		if result.err != nil {
+			return CheckResult{Status: StatusDown, Error: result.err.Error(), Details: result.details}
+		}
+		return CheckResult{Status: StatusUp, Details: result.details}
+	}
+}
+
+func (m *Module) respond(c *gin.Context, report Report) {
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+type indicator struct {
+	name  string
+	check func(ctx context.Context) (map[string]any, error)
+}
+
+func (i indicator) Name() string {
+	return i.name
+}
+
+func (i indicator) Check(ctx context.Context) (map[string]any, error) {
+	return i.check(ctx)
+}
+
+// NewIndicator creates an indicator from a check function.
+func NewIndicator(name string, check func(ctx context.Context) (map[string]any, error)) Indicator {
+	return indicator{name: name, check: check}
+}
+
+// Ping creates an indicator that is up when ping succeeds, such as a database's PingContext.
+func Ping(name string, ping func(ctx context.Context) error) Indicator {
+	return NewIndicator(name, func(ctx context.Context) (map[string]any, error) {
+		return nil, ping(ctx)
+	})
+}
+
+// Memory creates an indicator that is down when the heap in use exceeds maxHeapBytes.
+func Memory(maxHeapBytes uint64) Indicator {
+	return NewIndicator("memory", func(ctx context.Context) (map[string]any, error) {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		details := map[string]any{
+			"heapAlloc": stats.HeapAlloc,
+			"threshold": maxHeapBytes,
+		}
+		if stats.HeapAlloc > maxHeapBytes {
+			return details, fmt.Errorf("heap in use %d exceeds %d bytes", stats.HeapAlloc, maxHeapBytes)
+		}
+		return details, nil
+	})
+}
+
+// Goroutines creates an indicator that is down when more than max goroutines are running.
+func Goroutines(max int) Indicator {
+	return NewIndicator("goroutines", func(ctx context.Context) (map[string]any, error) {
+		count := runtime.NumGoroutine()
+
+		details := map[string]any{
+			"count":     count,
+			"threshold": max,
+		}
+		if count > max {
+			return details, fmt.Errorf("%d goroutines exceed %d", count, max)
+		}
+		return details, nil
+	})
+}
+
+// Disk creates an indicator that is down when the file system holding path has
+// less than minFreeBytes available.
+func Disk(path string, minFreeBytes uint64) Indicator {
+	return NewIndicator("disk", func(ctx context.Context) (map[string]any, error) {
+		free, total, err := diskUsage(path)
+		if err != nil {
+			return nil, err
+		}
+
+		details := map[string]any{
+			"path":      path,
+			"free":      free,
+			"total":     total,
+			"threshold": minFreeBytes,
+		}
+		if free < minFreeBytes {
+			return details, fmt.Errorf("%d bytes free on %s, below %d", free, path, minFreeBytes)
+		}
+		return details, nil
+	})
+}
@@ -0,0 +1,165 @@
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Params fill the placeholders of a message, such as {name} in "Hello, {name}".
+type Params map[string]any
+
+// Catalog holds the messages of every locale, by key.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewCatalog creates an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{messages: map[string]map[string]string{}}
+}
+
+// Add adds messages to a locale. Nested maps are flattened into dotted keys, so
+//
+//	{"users": {"not_found": "User {id} not found"}}
+//
+// adds the key "users.not_found".
+func (c *Catalog) Add(locale string, messages map[string]any) {
+	locale = normalizeLocale(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]string{}
+	}
+	flatten(c.messages[locale], "", messages)
+}
+
+// LoadFS adds the catalog files of dir in fsys, such as an embed.FS. Files are
+// named after their locale, such as "en.yaml" or "vi.json", and hold JSON or
+// YAML objects of messages.
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	for _, entry := range entries {
+		extension := path.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".json" && extension != ".yaml" && extension != ".yml") {
+			continue
+		}
+		name := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		var messages map[string]any
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("i18n: cannot parse %s: %w", name, err)
+		}
+		c.Add(strings.TrimSuffix(entry.Name(), extension), messages)
+	}
+	return nil
+}
+
+// LoadDir creates a catalog from the catalog files of a directory on disk, as LoadFS does.
+func LoadDir(dir string) (*Catalog, error) {
+	c := NewCatalog()
+	if err := c.LoadFS(os.DirFS(dir), "."); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Locales returns the locales of the catalog, sorted.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Has reports whether the catalog has messages for locale.
+func (c *Catalog) Has(locale string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, exists := c.messages[normalizeLocale(locale)]
+	return exists
+}
+
+// Lookup returns the message of key in locale, or in its base language for
+// regional locales such as "vi-VN", with its placeholders filled from params.
+func (c *Catalog) Lookup(locale string, key string, params Params) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locale = normalizeLocale(locale)
+	for _, candidate := range []string{locale, baseLanguage(locale)} {
+		if message, exists := c.messages[candidate][key]; exists {
+			return format(message, params), true
+		}
+	}
+	return "", false
+}
+
+// format replaces the placeholders of message with params. Unknown
+// placeholders are kept as written.
+func format(message string, params Params) string {
+	if len(params) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
+
+// flatten adds the messages of a nested map under prefix.
+func flatten(target map[string]string, prefix string, messages map[string]any) {
+	for key, value := range messages {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(target, key, value)
+		case map[any]any:
+			// YAML decodes maps with keys such as 404 this way
+			nested := make(map[string]any, len(value))
+			for nestedKey, nestedValue := range value {
+				nested[fmt.Sprint(nestedKey)] = nestedValue
+			}
+			flatten(target, key, nested)
+		case string:
+			target[key] = value
+		case nil:
+		default:
+			target[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// normalizeLocale turns "vi_VN" and "VI-vn" into "vi-VN".
+func normalizeLocale(locale string) string {
+	language, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !found {
+		return strings.ToLower(language)
+	}
+	return strings.ToLower(language) + "-" + strings.ToUpper(region)
+}
+
+// baseLanguage returns the language of a locale, such as "vi" for "vi-VN".
+func baseLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
@@ -0,0 +1,248 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/view"
+)
+
+// LOCALIZER is the context key holding the Localizer of the request.
+const LOCALIZER string = "ROUTIX_I18N_LOCALIZER"
+
+// Disabled turns off a locale source of Config, such as QueryParam.
+const Disabled = "-"
+
+// Config holds the settings of the i18n module. Empty settings use the
+// defaults documented on each field.
+type Config struct {
+	// Catalog holds the messages. Required.
+	Catalog *Catalog
+	// DefaultLocale is used when the request asks for no locale of the catalog,
+	// and for keys missing in the request locale. Defaults to "en".
+	DefaultLocale string
+	// QueryParam is the query param choosing the locale, such as ?lang=vi.
+	// Defaults to "lang"; Disabled ignores the query.
+	QueryParam string
+	// CookieName is the cookie remembering the locale. Defaults to "lang"; Disabled ignores cookies.
+	CookieName string
+}
+
+// Module resolves the locale of every request and translates messages to it.
+type Module struct {
+	config Config
+}
+
+// New creates an i18n module. Mount it with ServerConfig.I18n.
+func New(config Config) *Module {
+	if config.Catalog == nil {
+		panic("i18n: Config.Catalog is required")
+	}
+	if config.DefaultLocale == "" {
+		config.DefaultLocale = "en"
+	}
+	config.DefaultLocale = normalizeLocale(config.DefaultLocale)
+	if config.QueryParam == "" {
+		config.QueryParam = "lang"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "lang"
+	}
+	registerValidationTranslations(config.Catalog.Locales())
+	return &Module{config: config}
+}
+
+// Catalog returns the messages of the module.
+func (m *Module) Catalog() *Catalog {
+	return m.config.Catalog
+}
+
+// Localizer returns the localizer of a locale, or of the default locale when
+// the catalog does not have it.
+func (m *Module) Localizer(locale string) *Localizer {
+	if supported, ok := m.supported(locale); ok {
+		return &Localizer{module: m, locale: supported}
+	}
+	return &Localizer{module: m, locale: m.config.DefaultLocale}
+}
+
+// Middleware resolves the locale of the request from the query param, then the
+// cookie, then the Accept-Language header, and exposes the localizer to
+// handlers with From and to views as "t" and "locale":
+//
+//	<h1>{{ call .t "home.title" }}</h1>
+//	<p>{{ call .t "home.greeting" "name" .User.Name }}</p>
+func (m *Module) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		localizer := &Localizer{module: m, locale: m.resolve(c)}
+		c.Set(LOCALIZER, localizer)
+		view.Set(c, "t", localizer.Translate)
+		view.Set(c, "locale", localizer.locale)
+		c.Next()
+	}
+}
+
+// resolve returns the locale requested by the client among the catalog locales.
+func (m *Module) resolve(c *gin.Context) string {
+	if m.config.QueryParam != Disabled {
+		if locale, ok := m.supported(c.Query(m.config.QueryParam)); ok {
+			return locale
+		}
+	}
+	if m.config.CookieName != Disabled {
+		if cookie, err := c.Cookie(m.config.CookieName); err == nil {
+			if locale, ok := m.supported(cookie); ok {
+				return locale
+			}
+		}
+	}
+	for _, requested := range acceptedLanguages(c.GetHeader("Accept-Language")) {
+		if locale, ok := m.supported(requested); ok {
+			return locale
+		}
+	}
+	return m.config.DefaultLocale
+}
+
+// supported returns the catalog locale serving a requested locale: itself, or
+// its base language, so that "vi-VN" is served by "vi".
+func (m *Module) supported(requested string) (string, bool) {
+	if requested == "" || requested == "*" {
+		return "", false
+	}
+	locale := normalizeLocale(requested)
+	for _, candidate := range []string{locale, baseLanguage(locale)} {
+		if m.config.Catalog.Has(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// acceptedLanguages returns the languages of an Accept-Language header, most preferred first.
+func acceptedLanguages(header string) []string {
+	type language struct {
+		tag    string
+		weight float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		weight := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				weight = parsed
+			}
+		}
+		if weight > 0 {
+			languages = append(languages, language{tag: tag, weight: weight})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].weight > languages[j].weight
+	})
+	tags := make([]string, len(languages))
+	for i, language := range languages {
+		tags[i] = language.tag
+	}
+	return tags
+}
+
+// Localizer translates messages to the locale of a request.
+type Localizer struct {
+	module *Module
+	locale string
+}
+
+// From returns the localizer of the request, or nil when the i18n module is
+// not mounted. The methods of a nil localizer return keys and messages untranslated.
+func From(c *gin.Context) *Localizer {
+	if localizer, exists := c.Get(LOCALIZER); exists {
+		return localizer.(*Localizer)
+	}
+	return nil
+}
+
+// T translates key to the locale of the request, as Localizer.T does.
+func T(c *gin.Context, key string, params ...Params) string {
+	return From(c).T(key, params...)
+}
+
+// Locale returns the locale of the localizer, such as "vi".
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return ""
+	}
+	return l.locale
+}
+
+// Lookup returns the message of key, in the locale of the localizer or else
+// in the default locale, with its placeholders filled from params.
+func (l *Localizer) Lookup(key string, params ...Params) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	merged := Params{}
+	for _, param := range params {
+		for name, value := range param {
+			merged[name] = value
+		}
+	}
+	for _, locale := range []string{l.locale, l.module.config.DefaultLocale} {
+		if message, found := l.module.config.Catalog.Lookup(locale, key, merged); found {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// T returns the message of key with its placeholders filled from params, or
+// key itself when no locale has it, so missing translations show up.
+func (l *Localizer) T(key string, params ...Params) string {
+	if message, found := l.Lookup(key, params...); found {
+		return message
+	}
+	return key
+}
+
+// Translate is T taking params as name and value pairs, for templates:
+//
+//	{{ call .t "cart.items" "count" .Count }}
+func (l *Localizer) Translate(key string, pairs ...any) string {
+	params := Params{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		params[fmt.Sprint(pairs[i])] = pairs[i+1]
+	}
+	return l.T(key, params)
+}
+
+// Exception returns httpException with its message translated: a message that
+// is a catalog key is replaced by its translation, and the default message of
+// a status, such as "Not Found", by the "exceptions.<status>" message, such as
+// "exceptions.404".
+func (l *Localizer) Exception(httpException exception.HttpExceptionResponse) exception.HttpExceptionResponse {
+	if message, found := l.Lookup(httpException.Message); found {
+		httpException.Message = message
+	} else if httpException.Message == http.StatusText(httpException.Status) {
+		if message, found := l.Lookup("exceptions." + strconv.Itoa(httpException.Status)); found {
+			httpException.Message = message
+		}
+	}
+	return httpException
+}
+
+// Exception creates an exception whose message is the translation of key to
+// the locale of the request:
+//
+//	return i18n.Exception(c, http.StatusNotFound, "users.not_found", i18n.Params{"id": id})
+func Exception(c *gin.Context, status int, key string, params ...Params) exception.HttpExceptionResponse {
+	return exception.HttpException(status, From(c).T(key, params...))
+}
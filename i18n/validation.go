@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/vi"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	vi_translations "github.com/go-playground/validator/v10/translations/vi"
+)
+
+// ValidationLocale provides the validator messages of a language.
+type ValidationLocale struct {
+	// Locale formats numbers and plurals of the language, from github.com/go-playground/locales.
+	Locale locales.Translator
+	// Register registers the messages of the language, such as a
+	// RegisterDefaultTranslations function of github.com/go-playground/validator/v10/translations.
+	Register func(v *validator.Validate, trans ut.Translator) error
+}
+
+var validation = struct {
+	mu          sync.Mutex
+	locales     map[string]ValidationLocale
+	translators map[string]ut.Translator
+}{
+	locales: map[string]ValidationLocale{
+		"en": {Locale: en.New(), Register: en_translations.RegisterDefaultTranslations},
+		"vi": {Locale: vi.New(), Register: vi_translations.RegisterDefaultTranslations},
+	},
+	translators: map[string]ut.Translator{},
+}
+
+// RegisterValidationLocale adds the validator messages of a language, English
+// and Vietnamese being built in. Call it before creating the module:
+//
+//	i18n.RegisterValidationLocale("fr", i18n.ValidationLocale{Locale: fr.New(), Register: fr_translations.RegisterDefaultTranslations})
+func RegisterValidationLocale(language string, locale ValidationLocale) {
+	validation.mu.Lock()
+	defer validation.mu.Unlock()
+	validation.locales[normalizeLocale(language)] = locale
+}
+
+// registerValidationTranslations registers the validator messages of the
+// languages of catalogLocales on the validator gin binds with. Each language is
+// registered once.
+func registerValidationTranslations(catalogLocales []string) {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	validation.mu.Lock()
+	defer validation.mu.Unlock()
+	for _, catalogLocale := range catalogLocales {
+		language := baseLanguage(catalogLocale)
+		locale, known := validation.locales[language]
+		if _, registered := validation.translators[language]; registered || !known {
+			continue
+		}
+		translator, _ := ut.New(locale.Locale, locale.Locale).GetTranslator(locale.Locale.Locale())
+		if err := locale.Register(engine, translator); err != nil {
+			continue
+		}
+		validation.translators[language] = translator
+	}
+}
+
+// validationTranslator returns the translator of the language of locale.
+func validationTranslator(locale string) (ut.Translator, bool) {
+	validation.mu.Lock()
+	defer validation.mu.Unlock()
+	translator, exists := validation.translators[baseLanguage(locale)]
+	return translator, exists
+}
+
+// ValidationMessage translates the validator field errors of err, such as the
+// error of routix.Bind, joined with "; ". It returns false when err holds no
+// field errors.
+//
+// The "validation.<tag>" message of the catalog, such as "validation.required",
+// overrides the built-in message of a tag, with the {field}, {param} and
+// {value} placeholders. Field names are translated with the "fields.<Field>"
+// messages, such as "fields.Email".
+func (l *Localizer) ValidationMessage(err error) (string, bool) {
+	var fieldErrors validator.ValidationErrors
+	if l == nil || !errors.As(err, &fieldErrors) {
+		return "", false
+	}
+
+	translator, hasTranslator := validationTranslator(l.locale)
+	if !hasTranslator {
+		translator, hasTranslator = validationTranslator(l.module.config.DefaultLocale)
+	}
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		field, translated := l.Lookup("fields." + fieldError.Field())
+		if !translated {
+			field = fieldError.Field()
+		}
+		params := Params{"field": field, "param": fieldError.Param(), "value": fieldError.Value()}
+		if message, found := l.Lookup("validation."+fieldError.Tag(), params); found {
+			messages = append(messages, message)
+			continue
+		}
+		message := fieldError.Error()
+		if hasTranslator {
+			message = strings.Replace(fieldError.Translate(translator), fieldError.Field(), field, 1)
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, "; "), true
+}
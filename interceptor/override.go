@@ -0,0 +1,46 @@
+package interceptor
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/internal/funcname"
+)
+
+// OVERRIDES is the context key holding the interceptor replacements of the request.
+const OVERRIDES string = "ROUTIX_INTERCEPTOR_OVERRIDES"
+
+type overrides map[string]func(c *InterceptorContext) func()
+
+// Override returns a middleware that makes every UseInterceptor of the request
+// call replacement instead of original. A nil replacement skips original.
+//
+// Interceptors are matched by identity, as guards are by guard.Override.
+// Register the middleware before the routes run, with ServerConfig.Middlewares
+// or the routixtest options.
+func Override(original func(c *InterceptorContext) func(), replacement func(c *InterceptorContext) func()) gin.HandlerFunc {
+	id, _ := funcname.Identity(original)
+	if id == "" {
+		panic("interceptor: cannot identify the overridden function")
+	}
+	return func(c *gin.Context) {
+		value, _ := c.Get(OVERRIDES)
+		previous, _ := value.(overrides)
+		current := make(overrides, len(previous)+1)
+		for key, value := range previous {
+			current[key] = value
+		}
+		current[id] = replacement
+		c.Set(OVERRIDES, current)
+	}
+}
+
+// resolve returns the function to run in place of interceptorFunc, identified
+// by id, for the request, and false when it is disabled.
+func resolve(c *gin.Context, id string, interceptorFunc func(c *InterceptorContext) func()) (func(c *InterceptorContext) func(), bool) {
+	value, _ := c.Get(OVERRIDES)
+	table, _ := value.(overrides)
+	replacement, overridden := table[id]
+	if !overridden {
+		return interceptorFunc, true
+	}
+	return replacement, replacement != nil
+}
@@ -2,8 +2,16 @@ package interceptor
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/internal/funcname"
+	"github.com/l1ttps/routix/metadata"
+	"github.com/l1ttps/routix/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// INTERCEPTORS is the metadata key holding the name of the interceptor function
+// of a UseInterceptor middleware, such as "interceptors.LoggerInterceptor".
+const INTERCEPTORS string = "ROUTIX_INTERCEPTORS"
+
 type InterceptorContext struct {
 	*gin.Context
 }
@@ -21,18 +29,34 @@ type CallHandler func()
 // It takes a *gin.Context object as a parameter, creates a new InterceptorContext, and invokes the interceptor function with the InterceptorContext.
 // It then calls the Next() method on the *gin.Context to proceed to the next middleware.
 // Finally, it executes the nextHandler function returned by the interceptor function to execute the interceptor logic after the request is handled by other middlewares.
+//
+// The interceptor function may be replaced or skipped per request by Override.
 func UseInterceptor(interceptorFunc func(c *InterceptorContext) func()) gin.HandlerFunc {
-	return func(c *gin.Context) {
+	original := interceptorFunc
+	id, name := funcname.Identity(interceptorFunc)
+
+	return metadata.Attach(func(c *gin.Context) {
+		interceptorFunc, active := resolve(c, id, original)
+		if !active {
+			c.Next()
+			return
+		}
+
 		// Create a new InterceptorContext with the *gin.Context
 		context := InterceptorContext{c}
 
 		// Invoke the interceptor function and get the next handler
-		nextHandler := interceptorFunc(&context)
+		var nextHandler func()
+		tracing.Span(c, "interceptor "+name+" before", func(trace.Span) {
+			nextHandler = interceptorFunc(&context)
+		})
 
 		// Call the Next() method on the InterceptorContext to proceed to the next middleware
 		c.Next()
 
 		// Invoke the nextHandler to execute the interceptor logic after the request is handled by other middlewares
-		nextHandler()
-	}
+		tracing.Span(c, "interceptor "+name+" after", func(trace.Span) {
+			nextHandler()
+		})
+	}, INTERCEPTORS, []string{name})
 }
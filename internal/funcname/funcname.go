@@ -0,0 +1,85 @@
+package funcname
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Of returns the package-qualified name of a function, such as "guards.ProtectedGuard".
+//
+// It returns an empty string when the name cannot be resolved.
+func Of(fcn interface{}) string {
+	pc := reflect.ValueOf(fcn).Pointer()
+	funcInfo := runtime.FuncForPC(pc)
+	if funcInfo == nil {
+		return ""
+	}
+
+	return path.Base(funcInfo.Name())
+}
+
+// Identity returns the key identifying fcn when guards and interceptors are
+// overridden, and the name listing it, such as "guards.Roles". The key is
+// empty when fcn cannot be identified.
+//
+// Top-level functions, and function literals assigned to package-level
+// variables, are keyed by their code address, so that every one of them is
+// told apart. Closures created inside a function, such as the ones returned by
+// a factory like guards.Roles, are keyed by the source position of their
+// literal: the compiler gives a closure a different code address at every
+// place its factory is inlined, but all of them share that position. Keep
+// closures of one function on separate lines to tell them apart.
+func Identity(fcn interface{}) (key string, name string) {
+	pc := reflect.ValueOf(fcn).Pointer()
+	funcInfo := runtime.FuncForPC(pc)
+	if funcInfo == nil {
+		return "", ""
+	}
+
+	full := funcInfo.Name()
+	declaring := trimClosureSuffixes(full)
+	if declaring == full || isPackageLevel(declaring) {
+		return fmt.Sprintf("%s@%x", full, pc), path.Base(full)
+	}
+	file, line := funcInfo.FileLine(funcInfo.Entry())
+	if file == "" || file == "?" {
+		return "", ""
+	}
+	return fmt.Sprintf("%s:%d", file, line), path.Base(declaring)
+}
+
+// trimClosureSuffixes returns the name of the function declaring a closure,
+// such as "guards.Roles" for "guards.Roles.func1", or name itself for other functions.
+func trimClosureSuffixes(name string) string {
+	for {
+		cut := strings.LastIndex(name, ".")
+		if cut < 0 || cut < strings.LastIndex(name, "/") || !isClosureSuffix(name[cut+1:]) {
+			return name
+		}
+		name = name[:cut]
+	}
+}
+
+// isPackageLevel reports whether closures of the declaring function are
+// package-level function literals, declared as "pkg.init" or "pkg.glob.".
+func isPackageLevel(declaring string) bool {
+	return strings.HasSuffix(declaring, ".init") || strings.HasSuffix(declaring, ".glob.")
+}
+
+// isClosureSuffix reports whether a name segment is generated for a closure,
+// such as "func2" or "1".
+func isClosureSuffix(segment string) bool {
+	digits := strings.TrimPrefix(segment, "func")
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
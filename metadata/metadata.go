@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+)
+
+type entry struct {
+	// handler keeps the function value alive so its identity is never reused.
+	handler gin.HandlerFunc
+	values  map[string]any
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[uintptr]*entry{}
+)
+
+// Set returns a route middleware that stores value under key in the request context.
+//
+// Unlike a plain middleware, the pair is also recorded against the returned
+// handler, so it can be read when the route is registered (see Of), before any
+// request is served. Documentation generators and framework modules rely on
+// this to inspect routes statically.
+func Set(key string, value any) gin.HandlerFunc {
+	return Attach(func(c *gin.Context) {
+		c.Set(key, value)
+	}, key, value)
+}
+
+// Attach records key and value on an existing handler and returns the handler unchanged.
+//
+// Metadata is tied to the identity of the function value. Attaching to a
+// top-level function affects every route that uses it, so prefer attaching to
+// closures created for a single use.
+func Attach(handler gin.HandlerFunc, key string, value any) gin.HandlerFunc {
+	id := identity(handler)
+
+	mu.Lock()
+	defer mu.Unlock()
+	e, exists := registry[id]
+	if !exists {
+		e = &entry{handler: handler, values: map[string]any{}}
+		registry[id] = e
+	}
+	e.values[key] = value
+	return handler
+}
+
+// Of returns the metadata recorded on handler, or nil when it has none.
+func Of(handler gin.HandlerFunc) map[string]any {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, exists := registry[identity(handler)]
+	if !exists {
+		return nil
+	}
+	values := make(map[string]any, len(e.values))
+	for key, value := range e.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Collect merges the metadata of handlers in order, later handlers overriding earlier ones.
+func Collect(handlers ...gin.HandlerFunc) map[string]any {
+	values := map[string]any{}
+	for _, handler := range handlers {
+		for key, value := range Of(handler) {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// identity returns the address of the closure behind a function value, which
+// is unique for every closure created and for every top-level function.
+func identity(handler gin.HandlerFunc) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&handler))
+}
@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Counter is a monotonically increasing metric, partitioned by labels.
+type Counter struct {
+	family
+}
+
+// NewCounter creates a counter and registers it in the Default registry.
+func NewCounter(name, help string, labels ...string) *Counter {
+	counter := newCounter(name, help, labels...)
+	Default.Register(counter)
+	return counter
+}
+
+func newCounter(name, help string, labels ...string) *Counter {
+	return &Counter{newFamily(name, help, "counter", labels)}
+}
+
+// Inc adds 1 to the series identified by labelValues.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds value, which must not be negative, to the series identified by labelValues.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic("metrics: counter " + c.name + " cannot decrease")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.get(labelValues, func() any { return new(float64) }).(*float64) += value
+}
+
+// Write writes the counter. It implements Collector.
+func (c *Counter) Write(w io.Writer) error {
+	return c.snapshot(w, func(w io.Writer) error {
+		if err := c.writeHeader(w); err != nil {
+			return err
+		}
+		return c.each(func(labelValues []string, series any) error {
+			return writeSample(w, c.name, c.labels, labelValues, *series.(*float64))
+		})
+	})
+}
+
+func (c *Counter) matches(other Collector) bool {
+	registered, ok := other.(*Counter)
+	return ok && slices.Equal(registered.labels, c.labels)
+}
+
+// Gauge is a metric that can go up and down, partitioned by labels.
+type Gauge struct {
+	family
+}
+
+// NewGauge creates a gauge and registers it in the Default registry.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	gauge := newGauge(name, help, labels...)
+	Default.Register(gauge)
+	return gauge
+}
+
+func newGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{newFamily(name, help, "gauge", labels)}
+}
+
+// Set sets the series identified by labelValues to value.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	*g.get(labelValues, func() any { return new(float64) }).(*float64) = value
+}
+
+// Add adds value, which may be negative, to the series identified by labelValues.
+func (g *Gauge) Add(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	*g.get(labelValues, func() any { return new(float64) }).(*float64) += value
+}
+
+// Inc adds 1 to the series identified by labelValues.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts 1 from the series identified by labelValues.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Write writes the gauge. It implements Collector.
+func (g *Gauge) Write(w io.Writer) error {
+	return g.snapshot(w, func(w io.Writer) error {
+		if err := g.writeHeader(w); err != nil {
+			return err
+		}
+		return g.each(func(labelValues []string, series any) error {
+			return writeSample(w, g.name, g.labels, labelValues, *series.(*float64))
+		})
+	})
+}
+
+func (g *Gauge) matches(other Collector) bool {
+	registered, ok := other.(*Gauge)
+	return ok && slices.Equal(registered.labels, g.labels)
+}
+
+// Histogram samples observations into cumulative buckets, partitioned by labels.
+type Histogram struct {
+	family
+	buckets []float64
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram creates a histogram with the given upper bucket bounds and
+// registers it in the Default registry. DefaultBuckets is used when buckets is nil.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	histogram := newHistogram(name, help, buckets, labels...)
+	Default.Register(histogram)
+	return histogram
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{newFamily(name, help, "histogram", labels), buckets}
+}
+
+// Observe records value in the series identified by labelValues.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series := h.get(labelValues, func() any {
+		return &histogramSeries{counts: make([]uint64, len(h.buckets))}
+	}).(*histogramSeries)
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+// Write writes the histogram. It implements Collector.
+func (h *Histogram) Write(w io.Writer) error {
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	return h.snapshot(w, func(w io.Writer) error {
+		if err := h.writeHeader(w); err != nil {
+			return err
+		}
+		return h.each(func(labelValues []string, value any) error {
+			series := value.(*histogramSeries)
+			for i := 0; i <= len(h.buckets); i++ {
+				bound, count := math.Inf(1), series.count
+				if i < len(h.buckets) {
+					bound, count = h.buckets[i], series.counts[i]
+				}
+				bucketValues := append(append([]string(nil), labelValues...), formatValue(bound))
+				if err := writeSample(w, h.name+"_bucket", bucketLabels, bucketValues, float64(count)); err != nil {
+					return err
+				}
+			}
+			if err := writeSample(w, h.name+"_sum", h.labels, labelValues, series.sum); err != nil {
+				return err
+			}
+			return writeSample(w, h.name+"_count", h.labels, labelValues, float64(series.count))
+		})
+	})
+}
+
+func (h *Histogram) matches(other Collector) bool {
+	registered, ok := other.(*Histogram)
+	return ok && slices.Equal(registered.labels, h.labels) && slices.Equal(registered.buckets, h.buckets)
+}
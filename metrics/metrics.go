@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/guard"
+)
+
+// UNMATCHED is the route label of requests that did not match any route.
+const UNMATCHED string = "unmatched"
+
+// Config holds the settings of the metrics module.
+type Config struct {
+	// Path is the path of the exposition endpoint, relative to the server root
+	// rather than PathRoot. Defaults to "/metrics".
+	Path string
+	// Registry holds the exposed collectors. Defaults to Default, so metrics
+	// created with NewCounter, NewGauge and NewHistogram are exposed too.
+	Registry *Registry
+	// Namespace prefixes the names of the HTTP metrics. Defaults to "routix".
+	Namespace string
+	// Buckets are the latency histogram buckets, in seconds. Defaults to DefaultBuckets.
+	Buckets []float64
+}
+
+// Module records RED metrics for every route and exposes them for Prometheus.
+type Module struct {
+	config Config
+
+	requests   *Counter
+	duration   *Histogram
+	inFlight   *Gauge
+	rejections *Counter
+	exceptions *Counter
+}
+
+// New creates a metrics module and registers its HTTP metrics. Mount it with ServerConfig.Metrics.
+//
+// Modules created again on the same registry, such as by each app of a test,
+// share the collectors registered by the first one.
+func New(config Config) *Module {
+	if config.Path == "" {
+		config.Path = "/metrics"
+	}
+	if config.Registry == nil {
+		config.Registry = Default
+	}
+	if config.Namespace == "" {
+		config.Namespace = "routix"
+	}
+
+	prefix := config.Namespace + "_"
+	registry := config.Registry
+	return &Module{
+		config:     config,
+		requests:   reuse(registry, newCounter(prefix+"http_requests_total", "Total HTTP requests by method, route template and status.", "method", "route", "status")),
+		duration:   reuse(registry, newHistogram(prefix+"http_request_duration_seconds", "HTTP request latency in seconds by method and route template.", config.Buckets, "method", "route")),
+		inFlight:   reuse(registry, newGauge(prefix+"http_requests_in_flight", "HTTP requests currently being served by method and route template.", "method", "route")),
+		rejections: reuse(registry, newCounter(prefix+"guard_rejections_total", "Requests rejected by a guard by method and route template.", "method", "route")),
+		exceptions: reuse(registry, newCounter(prefix+"exceptions_total", "Exceptions returned by handlers by method, route template and exception type.", "method", "route", "exception")),
+	}
+}
+
+// reuse registers collector, or returns the collector registered under its
+// name by an earlier module, so that a module can be created again on the
+// same registry, such as on hot reload or by every app of a test. It panics
+// when the registered collector has another type, other labels or, for
+// histograms, other buckets.
+func reuse[T interface {
+	Collector
+	matches(other Collector) bool
+}](registry *Registry, collector T) T {
+	registered := registry.registerOrReuse(collector)
+	if !collector.matches(registered) {
+		panic(fmt.Sprintf("metrics: collector %q is already registered with another type, labels or buckets", collector.Name()))
+	}
+	return registered.(T)
+}
+
+// Path returns the path of the exposition endpoint.
+func (m *Module) Path() string {
+	return m.config.Path
+}
+
+// Middleware records the metrics of every request, labelled by route template
+// rather than raw path so that path parameters do not explode cardinality.
+func (m *Module) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			route = UNMATCHED
+		}
+
+		start := time.Now()
+		m.inFlight.Inc(method, route)
+		defer m.inFlight.Dec(method, route)
+
+		c.Next()
+
+		m.requests.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+		m.duration.Observe(time.Since(start).Seconds(), method, route)
+		if c.GetBool(guard.REJECTED) {
+			m.rejections.Inc(method, route)
+		}
+		if value, exists := c.Get(exception.EXCEPTION); exists {
+			m.exceptions.Inc(method, route, value.(exception.HttpExceptionResponse).Name())
+		}
+	}
+}
+
+// Handler serves the registry in the Prometheus text exposition format.
+func (m *Module) Handler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(200)
+	m.config.Registry.Write(c.Writer)
+}
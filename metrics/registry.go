@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the latency histogram buckets, in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector is a metric family that can be exposed in the Prometheus text format.
+type Collector interface {
+	// Name returns the metric family name.
+	Name() string
+	// Write writes the family, including its HELP and TYPE lines.
+	Write(w io.Writer) error
+}
+
+// Registry holds the collectors exposed by a metrics endpoint.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// Default is the registry used by New and by the metric constructors.
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]Collector{}}
+}
+
+// Register adds a collector to the registry. It panics when a collector with
+// the same name is already registered, like registering a route twice.
+func (r *Registry) Register(collector Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[collector.Name()]; exists {
+		panic(fmt.Sprintf("metrics: collector %q is already registered", collector.Name()))
+	}
+	r.collectors[collector.Name()] = collector
+}
+
+// registerOrReuse registers collector, or returns the collector of the same
+// name when one is registered already, which the caller checks for compatibility.
+func (r *Registry) registerOrReuse(collector Collector) Collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, exists := r.collectors[collector.Name()]; exists {
+		return existing
+	}
+	r.collectors[collector.Name()] = collector
+	return collector
+}
+
+// Unregister removes the collector with the given name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.collectors, name)
+}
+
+// Write writes every collector in the Prometheus text exposition format, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, collector := range r.collectors {
+		collectors = append(collectors, collector)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+
+	buffered := bufio.NewWriter(w)
+	for _, collector := range collectors {
+		if err := collector.Write(buffered); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// family holds the series of one metric, keyed by their label values.
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]any
+}
+
+func newFamily(name, help, kind string, labels []string) family {
+	return family{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: map[string]any{},
+	}
+}
+
+func (f *family) Name() string {
+	return f.name
+}
+
+// get returns the series for labelValues, creating it with create when missing.
+// The caller must hold f.mu.
+func (f *family) get(labelValues []string, create func() any) any {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	series, exists := f.series[key]
+	if !exists {
+		series = create()
+		f.series[key] = series
+	}
+	return series
+}
+
+// snapshot renders the family with render while holding f.mu, and writes the
+// result to w once f.mu is released, so that a slow scraper does not block the
+// updates of in-flight requests.
+func (f *family) snapshot(w io.Writer, render func(w io.Writer) error) error {
+	var buffer bytes.Buffer
+	f.mu.Lock()
+	err := render(&buffer)
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+// each calls fn for every series sorted by label values. The caller must hold f.mu.
+func (f *family) each(fn func(labelValues []string, series any) error) error {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var labelValues []string
+		if len(f.labels) > 0 {
+			labelValues = strings.Split(key, "\xff")
+		}
+		if err := fn(labelValues, f.series[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *family) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+	return err
+}
+
+func writeSample(w io.Writer, name string, labels []string, labelValues []string, value float64) error {
+	_, err := fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(labels, labelValues), formatValue(value))
+	return err
+}
+
+func formatLabels(labels []string, labelValues []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		pairs[i] = label + `="` + escapeLabelValue(labelValues[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
@@ -0,0 +1,370 @@
+package routix
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/i18n"
+	"github.com/l1ttps/routix/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	PRODUCES string = "ROUTIX_PRODUCES"
+	CONSUMES string = "ROUTIX_CONSUMES"
+)
+
+// MIMEYAML2 is the registered YAML media type, accepted alongside gin's application/x-yaml.
+const MIMEYAML2 = "application/yaml"
+
+// Encoder builds the gin renderer used to serialize data for one media type.
+// It returns false when data cannot be represented in that media type, in
+// which case the next acceptable media type is tried.
+type Encoder func(data any) (render.Render, bool)
+
+// Decoder binds the request body to obj. gin's binding.Binding implementations
+// satisfy this interface.
+type Decoder interface {
+	Bind(req *http.Request, obj any) error
+}
+
+type codecRegistry struct {
+	mu         sync.RWMutex
+	encoders   map[string]Encoder
+	encoderIDs []string
+	decoders   map[string]Decoder
+}
+
+var codecs = &codecRegistry{
+	encoders: map[string]Encoder{},
+	decoders: map[string]Decoder{},
+}
+
+func init() {
+	RegisterEncoder(binding.MIMEJSON, func(data any) (render.Render, bool) {
+		return render.JSON{Data: data}, true
+	})
+	RegisterEncoder(binding.MIMEXML, func(data any) (render.Render, bool) {
+		return render.XML{Data: data}, true
+	})
+	RegisterEncoder(binding.MIMEXML2, func(data any) (render.Render, bool) {
+		return render.XML{Data: data}, true
+	})
+	RegisterEncoder(binding.MIMEYAML, func(data any) (render.Render, bool) {
+		return render.YAML{Data: data}, true
+	})
+	RegisterEncoder(MIMEYAML2, func(data any) (render.Render, bool) {
+		return render.YAML{Data: data}, true
+	})
+	RegisterEncoder(binding.MIMETOML, func(data any) (render.Render, bool) {
+		return render.TOML{Data: data}, true
+	})
+	RegisterEncoder(binding.MIMEPROTOBUF, func(data any) (render.Render, bool) {
+		message, ok := data.(proto.Message)
+		return render.ProtoBuf{Data: message}, ok
+	})
+
+	RegisterDecoder(binding.MIMEJSON, binding.JSON)
+	RegisterDecoder(binding.MIMEXML, binding.XML)
+	RegisterDecoder(binding.MIMEXML2, binding.XML)
+	RegisterDecoder(binding.MIMEYAML, binding.YAML)
+	RegisterDecoder(MIMEYAML2, binding.YAML)
+	RegisterDecoder(binding.MIMETOML, binding.TOML)
+	RegisterDecoder(binding.MIMEPROTOBUF, binding.ProtoBuf)
+	RegisterDecoder(binding.MIMEPOSTForm, binding.Form)
+	RegisterDecoder(binding.MIMEMultipartPOSTForm, binding.FormMultipart)
+}
+
+// RegisterEncoder registers the encoder used for responses negotiated to the given media type.
+//
+// Registering a media type again replaces its encoder but keeps its original
+// priority. JSON is registered first and is therefore the default when the
+// client accepts anything.
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	mediaType = normalizeMediaType(mediaType)
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+	if _, exists := codecs.encoders[mediaType]; !exists {
+		codecs.encoderIDs = append(codecs.encoderIDs, mediaType)
+	}
+	codecs.encoders[mediaType] = encoder
+}
+
+// RegisterDecoder registers the decoder used by Bind for request bodies of the given media type.
+func RegisterDecoder(mediaType string, decoder Decoder) {
+	mediaType = normalizeMediaType(mediaType)
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+	codecs.decoders[mediaType] = decoder
+}
+
+// Produces restricts the media types a route may respond with.
+//
+// The types are listed in order of preference; the first one is used when the
+// client accepts anything. A request whose Accept header matches none of them
+// receives a 406 NotAcceptableException.
+func Produces(mediaTypes ...string) gin.HandlerFunc {
+	return metadata.Set(PRODUCES, normalizeMediaTypes(mediaTypes))
+}
+
+// Consumes restricts the request body media types Bind accepts for a route.
+func Consumes(mediaTypes ...string) gin.HandlerFunc {
+	return metadata.Set(CONSUMES, normalizeMediaTypes(mediaTypes))
+}
+
+// Bind decodes the request body into obj using the decoder registered for the request's Content-Type.
+//
+// It returns an UnsupportedMediaTypeException when no decoder matches (or the
+// route does not consume the media type) and a BadRequestException when
+// decoding or validation fails. Handlers can return the error as-is. With the
+// i18n module, validation messages are translated to the request locale.
+func Bind(c *gin.Context, obj any) error {
+	contentType := normalizeMediaType(c.ContentType())
+	if contentType == "" {
+		contentType = binding.MIMEJSON
+	}
+
+	if consumes, exists := c.Get(CONSUMES); exists && !containsMediaType(consumes.([]string), contentType) {
+		return exception.UnsupportedMediaTypeException()
+	}
+
+	codecs.mu.RLock()
+	decoder, exists := codecs.decoders[contentType]
+	codecs.mu.RUnlock()
+	if !exists {
+		return exception.UnsupportedMediaTypeException()
+	}
+
+	if err := decoder.Bind(c.Request, obj); err != nil {
+		// Such as the PayloadTooLargeException of an oversized body
+		var httpException exception.HttpExceptionResponse
+		if errors.As(err, &httpException) {
+			return httpException
+		}
+		if message, translated := i18n.From(c).ValidationMessage(err); translated {
+			return exception.BadRequestException(message)
+		}
+		return exception.BadRequestException(err.Error())
+	}
+	return nil
+}
+
+// Negotiate writes data with the given status using the best encoder for the request's Accept header.
+// When the encoder of a media type fails to render data, the next acceptable
+// media type is tried.
+//
+// It returns false without writing anything when none of the media types the
+// route produces is acceptable.
+func Negotiate(c *gin.Context, status int, data any) bool {
+	for _, mediaType := range acceptedMediaTypes(c) {
+		codecs.mu.RLock()
+		encoder, exists := codecs.encoders[mediaType]
+		codecs.mu.RUnlock()
+		if !exists {
+			continue
+		}
+		r, ok := encoder(data)
+		if !ok {
+			continue
+		}
+		if !bodyAllowedForStatus(status) {
+			c.Render(status, r)
+			return true
+		}
+		// Render into a buffer first, so that data the media type cannot
+		// represent, such as a map in XML, falls back to the next one
+		buffer := &bufferedWriter{header: http.Header{}}
+		if err := r.Render(buffer); err != nil {
+			continue
+		}
+		c.Data(status, buffer.header.Get("Content-Type"), buffer.Bytes())
+		return true
+	}
+	return false
+}
+
+// bufferedWriter collects a rendered body and its headers.
+type bufferedWriter struct {
+	bytes.Buffer
+	header http.Header
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(int) {}
+
+// respond negotiates data and falls back to a 406 exception when nothing is acceptable.
+func respond(c *gin.Context, status int, data any) {
+	if Negotiate(c, status, data) {
+		return
+	}
+	respondException(c, exception.NotAcceptableException())
+}
+
+// respondException writes an exception in the negotiated media type, using JSON
+// when the client accepts none of the available ones.
+func respondException(c *gin.Context, httpException exception.HttpExceptionResponse) {
+	c.Set(exception.EXCEPTION, httpException)
+	body := gin.H{
+		"status":  httpException.Status,
+		"message": httpException.Message,
+	}
+	if !Negotiate(c, httpException.Status, body) {
+		c.JSON(httpException.Status, body)
+	}
+}
+
+// offeredMediaTypes returns the media types the current route can produce in order of preference.
+func offeredMediaTypes(c *gin.Context) []string {
+	if produces, exists := c.Get(PRODUCES); exists {
+		return produces.([]string)
+	}
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+	return append([]string(nil), codecs.encoderIDs...)
+}
+
+// acceptedMediaTypes orders the offered media types by the client's preference,
+// dropping the ones the Accept header excludes.
+func acceptedMediaTypes(c *gin.Context) []string {
+	return rankMediaTypes(offeredMediaTypes(c), c.GetHeader("Accept"))
+}
+
+// rankMediaTypes orders offered by the preference expressed in an Accept header.
+// Ties and an empty header keep the offered order.
+//
+// Browsers send generic headers such as
+// "text/html,application/xml;q=0.9,*/*;q=0.8": the first offered type, when it
+// is accepted only through */*, goes ahead of the specific types ranked below
+// 1, so that routes keep answering their preferred type, JSON by default.
+func rankMediaTypes(offered []string, accept string) []string {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return offered
+	}
+
+	type candidate struct {
+		mediaType string
+		quality   float64
+		order     int
+		wildcard  bool
+	}
+
+	candidates := []candidate{}
+	for order, mediaType := range offered {
+		quality, specificity := -1.0, -1
+		for _, r := range ranges {
+			if s := r.match(mediaType); s > specificity {
+				quality, specificity = r.quality, s
+			}
+		}
+		if specificity >= 0 && quality > 0 {
+			candidates = append(candidates, candidate{mediaType, quality, order, specificity == 0})
+		}
+	}
+
+	tier := func(c candidate) int {
+		switch {
+		case c.quality >= 1:
+			return 0
+		case c.order == 0 && c.wildcard:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if tier(candidates[i]) != tier(candidates[j]) {
+			return tier(candidates[i]) < tier(candidates[j])
+		}
+		if candidates[i].quality != candidates[j].quality {
+			return candidates[i].quality > candidates[j].quality
+		}
+		return candidates[i].order < candidates[j].order
+	})
+
+	accepted := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		accepted[i] = candidate.mediaType
+	}
+	return accepted
+}
+
+type mediaRange struct {
+	mainType string
+	subType  string
+	quality  float64
+}
+
+// match reports how specifically the range matches mediaType: 2 for an exact
+// match, 1 for type/*, 0 for */* and -1 when it does not match.
+func (r mediaRange) match(mediaType string) int {
+	mainType, subType, _ := strings.Cut(mediaType, "/")
+	switch {
+	case r.mainType == "*":
+		return 0
+	case r.mainType != mainType:
+		return -1
+	case r.subType == "*":
+		return 1
+	case r.subType == subType:
+		return 2
+	}
+	return -1
+}
+
+// parseAccept parses an Accept header into its media ranges.
+func parseAccept(header string) []mediaRange {
+	ranges := []mediaRange{}
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		mainType, subType, found := strings.Cut(mediaType, "/")
+		if !found {
+			continue
+		}
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{mainType, subType, quality})
+	}
+	return ranges
+}
+
+func normalizeMediaType(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+func normalizeMediaTypes(mediaTypes []string) []string {
+	normalized := make([]string, len(mediaTypes))
+	for i, mediaType := range mediaTypes {
+		normalized[i] = normalizeMediaType(mediaType)
+	}
+	return normalized
+}
+
+func containsMediaType(mediaTypes []string, mediaType string) bool {
+	for _, candidate := range mediaTypes {
+		if candidate == mediaType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+//go:build !nomsgpack
+
+package routix
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+// MessagePack support follows gin and is left out when building with the nomsgpack tag.
+func init() {
+	RegisterEncoder(binding.MIMEMSGPACK, func(data any) (render.Render, bool) {
+		return render.MsgPack{Data: data}, true
+	})
+	RegisterEncoder(binding.MIMEMSGPACK2, func(data any) (render.Render, bool) {
+		return render.MsgPack{Data: data}, true
+	})
+
+	RegisterDecoder(binding.MIMEMSGPACK, binding.MsgPack)
+	RegisterDecoder(binding.MIMEMSGPACK2, binding.MsgPack)
+}
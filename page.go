@@ -0,0 +1,171 @@
+package routix
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/query"
+)
+
+// PageResponse is a page of a list endpoint. It responds with the items in a
+// standard envelope, the Link header of the neighbouring pages (RFC 8288) and
+// the X-Total-Count header when the total is known:
+//
+//	{"data": [...], "meta": {"total": 120, "limit": 20, "offset": 40, "page": 3, "pages": 6}}
+type PageResponse struct {
+	items      any
+	count      int
+	query      *query.Query
+	total      int
+	hasTotal   bool
+	nextCursor string
+	prevCursor string
+}
+
+// Page is the envelope written by PageResponse.
+type Page struct {
+	Data any      `json:"data" xml:"data" msgpack:"data"`
+	Meta PageMeta `json:"meta" xml:"meta" msgpack:"meta"`
+}
+
+// PageMeta describes the position of a page in its list.
+type PageMeta struct {
+	Total      *int   `json:"total,omitempty" xml:"total,omitempty" msgpack:"total,omitempty"`
+	Limit      int    `json:"limit" xml:"limit" msgpack:"limit"`
+	Offset     *int   `json:"offset,omitempty" xml:"offset,omitempty" msgpack:"offset,omitempty"`
+	Page       int    `json:"page,omitempty" xml:"page,omitempty" msgpack:"page,omitempty"`
+	Pages      *int   `json:"pages,omitempty" xml:"pages,omitempty" msgpack:"pages,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty" xml:"nextCursor,omitempty" msgpack:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty" xml:"prevCursor,omitempty" msgpack:"prevCursor,omitempty"`
+}
+
+// Paginate creates the page of items fetched for q, the query parsed by query.Parse:
+//
+//	q, err := query.Parse(c, query.Config{Sortable: []string{"name"}})
+//	if err != nil {
+//		return err
+//	}
+//	users, total := store.List(q)
+//	return routix.Paginate(users, q).Total(total)
+func Paginate[T any](items []T, q *query.Query) *PageResponse {
+	if items == nil {
+		items = []T{}
+	}
+	return &PageResponse{items: items, count: len(items), query: q}
+}
+
+// Total sets the number of items of the whole list, adding the last page link
+// and the X-Total-Count header.
+func (p *PageResponse) Total(total int) *PageResponse {
+	p.total, p.hasTotal = total, true
+	return p
+}
+
+// NextCursor sets the cursor of the next page in cursor pagination. The last
+// page has no next cursor.
+func (p *PageResponse) NextCursor(cursor string) *PageResponse {
+	p.nextCursor = cursor
+	return p
+}
+
+// PrevCursor sets the cursor of the previous page in cursor pagination.
+func (p *PageResponse) PrevCursor(cursor string) *PageResponse {
+	p.prevCursor = cursor
+	return p
+}
+
+// Respond writes the page. It implements Responder.
+func (p *PageResponse) Respond(c *gin.Context) {
+	q := p.query
+	if q == nil {
+		q = &query.Query{Mode: query.OffsetMode, Limit: p.count, Page: 1}
+	}
+	if q.Limit <= 0 {
+		// Such as a Query built by hand rather than by query.Parse
+		fallback := *q
+		fallback.Limit = query.DefaultLimit
+		q = &fallback
+	}
+	meta := PageMeta{Limit: q.Limit}
+
+	var links []string
+	link := func(rel string, params map[string]string) {
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", pageURL(c, params), rel))
+	}
+
+	if q.Mode == query.CursorMode {
+		meta.NextCursor, meta.PrevCursor = p.nextCursor, p.prevCursor
+		if p.prevCursor != "" {
+			link("prev", map[string]string{"cursor": p.prevCursor})
+		}
+		if p.nextCursor != "" {
+			link("next", map[string]string{"cursor": p.nextCursor})
+		}
+	} else {
+		// Page links keep the style of the request: ?page=&size= or ?offset=&limit=
+		at := func(offset int) map[string]string {
+			if q.Mode == query.PageMode {
+				return map[string]string{"page": strconv.Itoa(offset/q.Limit + 1)}
+			}
+			return map[string]string{"offset": strconv.Itoa(offset)}
+		}
+		offset := q.Offset
+		meta.Page = q.Page
+		if q.Mode == query.OffsetMode {
+			meta.Offset = &offset
+		}
+
+		hasNext := p.count >= q.Limit && q.Limit > 0
+		lastOffset := -1
+		if p.hasTotal {
+			total, pages := p.total, 0
+			if q.Limit > 0 {
+				pages = (total + q.Limit - 1) / q.Limit
+			}
+			meta.Total, meta.Pages = &total, &pages
+			hasNext = offset+q.Limit < total
+			if pages > 0 {
+				lastOffset = (pages - 1) * q.Limit
+			}
+		}
+
+		link("first", at(0))
+		if offset > 0 {
+			link("prev", at(max(offset-q.Limit, 0)))
+		}
+		if hasNext {
+			link("next", at(offset+q.Limit))
+		}
+		if lastOffset >= 0 {
+			link("last", at(lastOffset))
+		}
+	}
+
+	response := Respond(Page{Data: p.items, Meta: meta})
+	if len(links) > 0 {
+		response.Header("Link", strings.Join(links, ", "))
+	}
+	if p.hasTotal {
+		response.Header("X-Total-Count", strconv.Itoa(p.total))
+	}
+	response.Respond(c)
+}
+
+// pageURL returns the URL of the request with params replaced, keeping its
+// other query params such as sort and filter.
+func pageURL(c *gin.Context, params map[string]string) string {
+	values := c.Request.URL.Query()
+	for _, name := range []string{"cursor", "page", "offset"} {
+		if _, replaced := params[name]; !replaced {
+			values.Del(name)
+		}
+	}
+	for name, value := range params {
+		values.Set(name, value)
+	}
+	target := url.URL{Path: c.Request.URL.Path, RawQuery: values.Encode()}
+	return target.String()
+}
@@ -0,0 +1,52 @@
+package routix
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/payload"
+)
+
+// payloadLimits holds the body size limit of every registered route set with payload.Limit, by "METHOD path".
+var payloadLimits map[string]int64
+
+// usePayload applies the middleware limiting and decompressing request bodies.
+// Oversized bodies are answered with a 413 exception and unsupported encodings
+// with a 415 exception. Without a module, only the limits set on routes, such
+// as by upload.Multipart, are enforced.
+func usePayload(module *payload.Module) {
+	payloadLimits = map[string]int64{}
+
+	// Filled by resolvePayloadLimits once the routes of this server are registered
+	limits := payloadLimits
+	Driver.Use(func(c *gin.Context) {
+		limit, exists := limits[c.Request.Method+" "+c.FullPath()]
+		var err error
+		if module != nil {
+			if !exists {
+				limit = module.MaxSize()
+			}
+			err = module.Prepare(c, limit)
+		} else if exists {
+			err = payload.LimitBody(c, limit)
+		}
+		if err != nil {
+			var httpException exception.HttpExceptionResponse
+			if !errors.As(err, &httpException) {
+				httpException = exception.BadRequestException(err.Error())
+			}
+			appOf(c).errorResponder(c, httpException)
+			c.Abort()
+		}
+	})
+}
+
+// resolvePayloadLimits records the body size limits set by the metadata of the registered routes.
+func resolvePayloadLimits() {
+	for _, route := range registeredRoutes {
+		if limit, exists := route.Metadata[payload.LIMIT]; exists {
+			payloadLimits[string(route.Method)+" "+route.Path] = limit.(int64)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package payload
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/metadata"
+)
+
+// LIMIT is the metadata key set by Limit on routes with their own body size limit.
+const LIMIT string = "ROUTIX_PAYLOAD_LIMIT"
+
+// Unlimited disables a size limit, as Config.MaxSize or with Limit.
+const Unlimited int64 = -1
+
+// ratioThreshold is the decoded size below which the ratio limit is not
+// checked, as small bodies of repetitive text compress far beyond any sane ratio.
+const ratioThreshold = 64 << 10
+
+// decoders create the readers decoding the supported content codings.
+var decoders = map[string]func(r io.Reader) (io.Reader, error){
+	"gzip": func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+	// The deflate coding of HTTP is the zlib format (RFC 9110)
+	"deflate": func(r io.Reader) (io.Reader, error) {
+		return zlib.NewReader(r)
+	},
+	"br": func(r io.Reader) (io.Reader, error) {
+		return brotli.NewReader(r), nil
+	},
+}
+
+// Config holds the settings of the payload module. Empty settings use the
+// defaults documented on each field.
+type Config struct {
+	// MaxSize is the largest size of a request body in bytes, after
+	// decompression. Routes override it with Limit. Defaults to 4 MiB;
+	// Unlimited disables it.
+	MaxSize int64
+	// MaxRatio is the largest ratio between the decompressed and compressed
+	// sizes of a body, protecting against decompression bombs. Defaults to 100.
+	MaxRatio float64
+	// Encodings lists the accepted Content-Encoding codings among "gzip",
+	// "deflate" and "br". Defaults to all of them. Bodies with other codings
+	// are answered with a 415 exception.
+	Encodings []string
+}
+
+// Module limits the size of request bodies and decompresses them.
+type Module struct {
+	config Config
+}
+
+// New creates a payload module. Mount it with ServerConfig.Payload.
+func New(config Config) *Module {
+	if config.MaxSize == 0 {
+		config.MaxSize = 4 << 20
+	}
+	if config.MaxRatio <= 0 {
+		config.MaxRatio = 100
+	}
+	if len(config.Encodings) == 0 {
+		config.Encodings = []string{"gzip", "deflate", "br"}
+	}
+	for _, encoding := range config.Encodings {
+		if _, supported := decoders[encoding]; !supported {
+			panic(fmt.Sprintf("payload: unsupported encoding %q", encoding))
+		}
+	}
+	return &Module{config: config}
+}
+
+// MaxSize returns the body size limit of routes without their own.
+func (m *Module) MaxSize() int64 {
+	return m.config.MaxSize
+}
+
+// Limit returns a route middleware setting the body size limit of the route,
+// such as a larger one for uploads:
+//
+//	routix.Post("/videos", uploadVideo, payload.Limit(512<<20))
+//
+// The limit is enforced by the module mounted with ServerConfig.Payload, and
+// with LimitBody on servers without one.
+func Limit(size int64) gin.HandlerFunc {
+	return metadata.Set(LIMIT, size)
+}
+
+// Prepare checks the body of the request against limit and replaces it with a
+// reader decoding its Content-Encoding and enforcing the limits while the
+// handler reads it. Reading past a limit fails with a PayloadTooLargeException.
+//
+// Prepare returns a PayloadTooLargeException when the Content-Length already
+// exceeds limit, and an UnsupportedMediaTypeException listing the accepted
+// codings in the Accept-Encoding response header for other codings.
+func (m *Module) Prepare(c *gin.Context, limit int64) error {
+	request := c.Request
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
+	if limit >= 0 && request.ContentLength > limit {
+		return tooLarge(limit)
+	}
+
+	var codings []string
+	for _, coding := range strings.Split(request.Header.Get("Content-Encoding"), ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" || coding == "identity" {
+			continue
+		}
+		if !m.accepts(coding) {
+			c.Header("Accept-Encoding", strings.Join(m.config.Encodings, ", "))
+			return exception.UnsupportedMediaTypeException(fmt.Sprintf("Unsupported Content-Encoding %q", coding))
+		}
+		codings = append(codings, coding)
+	}
+
+	body := &limitedBody{
+		compressed: &countingReader{reader: request.Body},
+		closer:     request.Body,
+		limit:      limit,
+		maxRatio:   m.config.MaxRatio,
+	}
+	body.reader = body.compressed
+	// Codings are listed in the order they were applied
+	for i := len(codings) - 1; i >= 0; i-- {
+		decoded, err := decoders[codings[i]](body.reader)
+		if err != nil {
+			return exception.BadRequestException(fmt.Sprintf("Invalid %s body: %v", codings[i], err))
+		}
+		body.reader = decoded
+	}
+	if len(codings) > 0 {
+		// The decoded length is unknown until the body is read
+		request.Header.Del("Content-Encoding")
+		request.Header.Del("Content-Length")
+		request.ContentLength = -1
+	} else {
+		body.maxRatio = 0
+	}
+	request.Body = body
+	return nil
+}
+
+// LimitBody checks the body of the request against limit and makes reading
+// past it fail with a PayloadTooLargeException, leaving the body encoded. It
+// enforces the limits of routes on servers without a payload module.
+func LimitBody(c *gin.Context, limit int64) error {
+	request := c.Request
+	if limit < 0 || request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
+	if request.ContentLength > limit {
+		return tooLarge(limit)
+	}
+	raw := &countingReader{reader: request.Body}
+	request.Body = &limitedBody{reader: raw, compressed: raw, closer: request.Body, limit: limit}
+	return nil
+}
+
+func (m *Module) accepts(coding string) bool {
+	for _, encoding := range m.config.Encodings {
+		if encoding == coding {
+			return true
+		}
+	}
+	return false
+}
+
+func tooLarge(limit int64) exception.HttpExceptionResponse {
+	return exception.PayloadTooLargeException(fmt.Sprintf("Request body exceeds %d bytes", limit))
+}
+
+// countingReader counts the bytes read from the raw body.
+type countingReader struct {
+	reader io.Reader
+	read   int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+// limitedBody reads the decoded body, failing once it exceeds the size limit
+// or the decompression ratio.
+type limitedBody struct {
+	reader     io.Reader
+	compressed *countingReader
+	closer     io.Closer
+	limit      int64
+	maxRatio   float64
+	read       int64
+	err        error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.limit >= 0 && int64(len(p)) > b.limit-b.read+1 {
+		p = p[:b.limit-b.read+1]
+	}
+	n, err := b.reader.Read(p)
+	b.read += int64(n)
+	switch {
+	case b.limit >= 0 && b.read > b.limit:
+		b.err = tooLarge(b.limit)
+	case b.maxRatio > 0 && b.read > ratioThreshold && float64(b.read) > b.maxRatio*float64(b.compressed.read):
+		b.err = exception.PayloadTooLargeException(fmt.Sprintf("Request body exceeds the decompression ratio of %g", b.maxRatio))
+	default:
+		return n, err
+	}
+	return 0, b.err
+}
+
+func (b *limitedBody) Close() error {
+	return b.closer.Close()
+}
@@ -0,0 +1,154 @@
+package pipe
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errRequired is returned by the parsing pipes for missing params.
+var errRequired = errors.New("is required")
+
+// DefaultValue returns a pipe replacing a missing or empty param with value.
+// Put it before the parsing pipes, which accept values of their own type:
+//
+//	pipe.Query("limit", pipe.DefaultValue(20), pipe.ParseInt())
+func DefaultValue(value any) Pipe {
+	return func(current any) (any, error) {
+		if current == nil {
+			return value, nil
+		}
+		return current, nil
+	}
+}
+
+// ParseInt returns a pipe converting the param to an int.
+func ParseInt() Pipe {
+	return Transform(func(value string) (int, error) {
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, errors.New("must be an integer")
+		}
+		return number, nil
+	})
+}
+
+// ParseFloat returns a pipe converting the param to a float64.
+func ParseFloat() Pipe {
+	return Transform(func(value string) (float64, error) {
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, errors.New("must be a number")
+		}
+		return number, nil
+	})
+}
+
+// ParseBool returns a pipe converting the param to a bool. It accepts the
+// values of strconv.ParseBool, such as "true", "1" and "false".
+func ParseBool() Pipe {
+	return Transform(func(value string) (bool, error) {
+		flag, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return false, errors.New("must be a boolean")
+		}
+		return flag, nil
+	})
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ParseUUID returns a pipe checking that the param is a UUID, such as
+// "3f2504e0-4f89-41d3-9a0c-0305e82c3301", and lowercasing it.
+func ParseUUID() Pipe {
+	return Transform(func(value string) (string, error) {
+		if !uuidPattern.MatchString(value) {
+			return "", errors.New("must be a UUID")
+		}
+		return strings.ToLower(value), nil
+	})
+}
+
+// ParseEnum returns a pipe checking that the param is one of values, and
+// converting it to their type:
+//
+//	type Status string
+//	pipe.Query("status", pipe.ParseEnum[Status]("active", "archived"))
+func ParseEnum[T ~string](values ...T) Pipe {
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = strconv.Quote(string(value))
+	}
+	expected := fmt.Errorf("must be one of %s", strings.Join(names, ", "))
+	return Transform(func(value string) (T, error) {
+		for _, allowed := range values {
+			if string(allowed) == value {
+				return allowed, nil
+			}
+		}
+		return "", expected
+	})
+}
+
+// ParseDate returns a pipe converting the param to a time.Time with the first
+// of layouts it matches. Layouts default to RFC 3339 and "2006-01-02".
+func ParseDate(layouts ...string) Pipe {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339, time.DateOnly}
+	}
+	expected := fmt.Errorf("must be a date formatted as %s", strings.Join(layouts, " or "))
+	return Transform(func(value string) (time.Time, error) {
+		for _, layout := range layouts {
+			if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return date, nil
+			}
+		}
+		return time.Time{}, expected
+	})
+}
+
+// Transform returns a pipe converting the raw param with parse, for custom
+// pipes. Missing params fail with "is required", and values already of type T,
+// such as a DefaultValue, are passed through:
+//
+//	var parseCursor = pipe.Transform(func(value string) (Cursor, error) { ... })
+func Transform[T any](parse func(value string) (T, error)) Pipe {
+	return func(value any) (any, error) {
+		switch value := value.(type) {
+		case nil:
+			return nil, errRequired
+		case string:
+			return parse(value)
+		case T:
+			return value, nil
+		default:
+			return parse(fmt.Sprint(value))
+		}
+	}
+}
+
+// Check returns a pipe validating the value produced by the previous pipes
+// with valid, for constraints such as ranges. message describes what is
+// expected, such as "must be between 1 and 100".
+//
+//	pipe.Query("limit", pipe.DefaultValue(20), pipe.ParseInt(), pipe.Check(func(limit int) bool {
+//		return limit >= 1 && limit <= 100
+//	}, "must be between 1 and 100"))
+func Check[T any](valid func(value T) bool, message string) Pipe {
+	return func(value any) (any, error) {
+		typed, ok := value.(T)
+		if !ok {
+			if value == nil {
+				return nil, errRequired
+			}
+			return nil, errors.New(message)
+		}
+		if !valid(typed) {
+			return nil, errors.New(message)
+		}
+		return value, nil
+	}
+}
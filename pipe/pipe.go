@@ -0,0 +1,134 @@
+package pipe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+)
+
+// VALUES is the context key holding the values produced by the pipes of the request, by param name.
+const VALUES string = "ROUTIX_PIPE_VALUES"
+
+// FAILED is the context key holding the BadRequestException of the first pipe
+// that failed. The route handler is skipped and the exception is returned instead.
+const FAILED string = "ROUTIX_PIPE_FAILED"
+
+// Pipe transforms and validates a param value. The value is the raw string,
+// or nil when the param is missing, or the result of the previous pipe. The
+// returned error describes what is expected, such as "must be an integer".
+// Returning an exception.HttpExceptionResponse answers with it as is.
+type Pipe func(value any) (any, error)
+
+// Source is where a param is read from.
+type Source string
+
+const (
+	// PathSource reads route params, such as id in "/users/:id".
+	PathSource Source = "path"
+	// QuerySource reads query string params.
+	QuerySource Source = "query"
+	// HeaderSource reads request headers.
+	HeaderSource Source = "header"
+)
+
+// Param returns a route middleware running pipes on the route param name:
+//
+//	routix.Get("/:id", getUser, pipe.Param("id", pipe.ParseInt()))
+func Param(name string, pipes ...Pipe) gin.HandlerFunc {
+	return use(PathSource, name, pipes, func(c *gin.Context) (string, bool) {
+		for _, param := range c.Params {
+			if param.Key == name {
+				return param.Value, true
+			}
+		}
+		return "", false
+	})
+}
+
+// Query returns a route middleware running pipes on the query param name:
+//
+//	routix.Get("/", listUsers, pipe.Query("page", pipe.DefaultValue(1), pipe.ParseInt()))
+func Query(name string, pipes ...Pipe) gin.HandlerFunc {
+	return use(QuerySource, name, pipes, func(c *gin.Context) (string, bool) {
+		return c.GetQuery(name)
+	})
+}
+
+// Header returns a route middleware running pipes on the request header name.
+func Header(name string, pipes ...Pipe) gin.HandlerFunc {
+	return use(HeaderSource, name, pipes, func(c *gin.Context) (string, bool) {
+		value := c.GetHeader(name)
+		return value, value != ""
+	})
+}
+
+// use returns the middleware running pipes on the value read by lookup.
+func use(source Source, name string, pipes []Pipe, lookup func(c *gin.Context) (string, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Only the first failure of a request is reported
+		if _, failed := c.Get(FAILED); failed {
+			return
+		}
+
+		var value any
+		if raw, exists := lookup(c); exists && raw != "" {
+			value = raw
+		}
+		for _, pipe := range pipes {
+			result, err := pipe(value)
+			if err != nil {
+				c.Set(FAILED, failure(source, name, err))
+				return
+			}
+			value = result
+		}
+
+		values, _ := c.Get(VALUES)
+		current, ok := values.(map[string]any)
+		if !ok {
+			current = map[string]any{}
+			c.Set(VALUES, current)
+		}
+		current[name] = value
+	}
+}
+
+// failure turns the error of a pipe into the exception answering the request.
+func failure(source Source, name string, err error) exception.HttpExceptionResponse {
+	var httpException exception.HttpExceptionResponse
+	if errors.As(err, &httpException) {
+		return httpException
+	}
+	return exception.BadRequestException(fmt.Sprintf("Invalid %s param %q: %v", source, name, err))
+}
+
+// Value returns the value produced by the pipes of the param name, or the zero
+// value of T when the param has no pipes or produced another type.
+//
+//	id := pipe.Value[int](c, "id")
+func Value[T any](c *gin.Context, name string) T {
+	value, _ := Lookup[T](c, name)
+	return value
+}
+
+// Lookup returns the value produced by the pipes of the param name, and
+// whether it exists with type T.
+func Lookup[T any](c *gin.Context, name string) (T, bool) {
+	var zero T
+	values, _ := c.Get(VALUES)
+	current, _ := values.(map[string]any)
+	value, ok := current[name].(T)
+	if !ok {
+		return zero, false
+	}
+	return value, true
+}
+
+// Failure returns the exception of the first pipe that failed on the request.
+func Failure(c *gin.Context) (exception.HttpExceptionResponse, bool) {
+	value, _ := c.Get(FAILED)
+	httpException, failed := value.(exception.HttpExceptionResponse)
+	return httpException, failed
+}
@@ -0,0 +1,451 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kind is the type of a filterable field or of a filter value.
+type Kind string
+
+const (
+	StringKind Kind = "string"
+	NumberKind Kind = "number"
+	BoolKind   Kind = "bool"
+	// TimeKind fields take RFC 3339 date-times or dates written as strings.
+	TimeKind Kind = "time"
+	NullKind Kind = "null"
+	ListKind Kind = "list"
+)
+
+// Operator compares a field with a value.
+type Operator string
+
+const (
+	Eq         Operator = "eq"
+	Ne         Operator = "ne"
+	Gt         Operator = "gt"
+	Ge         Operator = "ge"
+	Lt         Operator = "lt"
+	Le         Operator = "le"
+	In         Operator = "in"
+	Contains   Operator = "contains"
+	StartsWith Operator = "startswith"
+)
+
+// operators maps the spellings of the filter grammar to operators.
+var operators = map[string]Operator{
+	"eq": Eq, "=": Eq, "==": Eq,
+	"ne": Ne, "!=": Ne,
+	"gt": Gt, ">": Gt,
+	"ge": Ge, ">=": Ge,
+	"lt": Lt, "<": Lt,
+	"le": Le, "<=": Le,
+	"in":         In,
+	"contains":   Contains,
+	"startswith": StartsWith,
+}
+
+// Expr is a node of a filter expression: And, Or, Not or Comparison. Handlers
+// translate it into queries with a type switch.
+type Expr interface {
+	String() string
+	expr()
+}
+
+// And matches when both sides match.
+type And struct {
+	Left, Right Expr
+}
+
+// Or matches when either side matches.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not matches when Expr does not.
+type Not struct {
+	Expr Expr
+}
+
+// Comparison compares a field with a value, such as age gt 30.
+type Comparison struct {
+	Field    string
+	Operator Operator
+	Value    Value
+}
+
+// Value is a literal of a filter expression.
+type Value struct {
+	Kind   Kind
+	Text   string
+	Number float64
+	Bool   bool
+	Time   time.Time
+	// List holds the values of the in operator.
+	List []Value
+}
+
+func (And) expr()        {}
+func (Or) expr()         {}
+func (Not) expr()        {}
+func (Comparison) expr() {}
+
+func (e And) String() string { return "(" + e.Left.String() + " and " + e.Right.String() + ")" }
+func (e Or) String() string  { return "(" + e.Left.String() + " or " + e.Right.String() + ")" }
+func (e Not) String() string { return "not " + e.Expr.String() }
+func (e Comparison) String() string {
+	return e.Field + " " + string(e.Operator) + " " + e.Value.String()
+}
+
+// Any returns the Go value of v: a string, float64, bool, time.Time, nil or []any.
+func (v Value) Any() any {
+	switch v.Kind {
+	case StringKind:
+		return v.Text
+	case NumberKind:
+		return v.Number
+	case BoolKind:
+		return v.Bool
+	case TimeKind:
+		return v.Time
+	case ListKind:
+		values := make([]any, len(v.List))
+		for i, value := range v.List {
+			values[i] = value.Any()
+		}
+		return values
+	}
+	return nil
+}
+
+// String returns v as written in the filter grammar.
+func (v Value) String() string {
+	switch v.Kind {
+	case StringKind, TimeKind:
+		return "'" + strings.ReplaceAll(v.Text, "'", "''") + "'"
+	case NumberKind, BoolKind:
+		return v.Text
+	case ListKind:
+		values := make([]string, len(v.List))
+		for i, value := range v.List {
+			values[i] = value.String()
+		}
+		return "(" + strings.Join(values, ", ") + ")"
+	}
+	return "null"
+}
+
+// ParseFilter parses a filter expression, such as
+//
+//	status eq 'active' and (age ge 18 or not role in ('guest', 'banned'))
+//
+// against the filterable fields and their kinds. Comparisons are joined with
+// and, or and not, and grouped with parentheses; and binds tighter than or.
+// Operators are eq, ne, gt, ge, lt, le (or =, !=, >, >=, <, <=), in,
+// contains and startswith. Values are quoted strings, numbers, true, false
+// and null. A nil fields map accepts no field.
+func ParseFilter(filter string, fields map[string]Kind) (Expr, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != endToken {
+		return nil, fmt.Errorf("unexpected %s at position %d", next, next.position)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	wordToken
+	stringToken
+	numberToken
+	operatorToken
+	openToken
+	closeToken
+	commaToken
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case endToken:
+		return "end of filter"
+	case stringToken:
+		return fmt.Sprintf("string '%s'", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenize splits a filter expression into tokens.
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: openToken, text: "(", position: start})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: closeToken, text: ")", position: start})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: commaToken, text: ",", position: start})
+			i++
+		case r == '\'' || r == '"':
+			// Quotes are escaped by doubling them, as in SQL
+			var text strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						text.WriteRune(r)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: stringToken, text: text.String(), position: start})
+		case strings.ContainsRune("=!<>", r):
+			for i < len(runes) && strings.ContainsRune("=!<>", runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, known := operators[text]; !known {
+				return nil, fmt.Errorf("unknown operator %q at position %d", text, start)
+			}
+			tokens = append(tokens, token{kind: operatorToken, text: text, position: start})
+		case r == '-' || r == '+' || unicode.IsDigit(r):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE+-", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: string(runes[start:i]), position: start})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: wordToken, text: string(runes[start:i]), position: start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, start)
+		}
+	}
+	return append(tokens, token{kind: endToken, position: len(runes)}), nil
+}
+
+// parser is a recursive descent parser of the filter grammar:
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = field operator ( value | "(" value { "," value } ")" )
+type parser struct {
+	tokens []token
+	next   int
+	fields map[string]Kind
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != endToken {
+		p.next++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the keyword word, consuming it.
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == wordToken && strings.EqualFold(t.text, word) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.keyword("not") {
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+	if p.peek().kind == openToken {
+		p.advance()
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.advance(); t.kind != closeToken {
+			return nil, fmt.Errorf("expected \")\" at position %d, got %s", t.position, t)
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	t := p.advance()
+	if t.kind != wordToken {
+		return nil, fmt.Errorf("expected a field at position %d, got %s", t.position, t)
+	}
+	kind, filterable := p.fields[t.text]
+	if !filterable {
+		return nil, fmt.Errorf("cannot filter by %q, filterable fields: %s", t.text, strings.Join(sortedFields(p.fields), ", "))
+	}
+	comparison := Comparison{Field: t.text}
+
+	t = p.advance()
+	operator, known := operators[strings.ToLower(t.text)]
+	if (t.kind != wordToken && t.kind != operatorToken) || !known {
+		return nil, fmt.Errorf("expected an operator after %q at position %d, got %s", comparison.Field, t.position, t)
+	}
+	comparison.Operator = operator
+
+	if operator == In {
+		if t := p.advance(); t.kind != openToken {
+			return nil, fmt.Errorf("expected \"(\" after in at position %d, got %s", t.position, t)
+		}
+		list := Value{Kind: ListKind}
+		for {
+			value, err := p.value(comparison.Field, kind)
+			if err != nil {
+				return nil, err
+			}
+			list.List = append(list.List, value)
+			if t := p.advance(); t.kind == closeToken {
+				break
+			} else if t.kind != commaToken {
+				return nil, fmt.Errorf("expected \",\" or \")\" at position %d, got %s", t.position, t)
+			}
+		}
+		comparison.Value = list
+		return comparison, nil
+	}
+
+	value, err := p.value(comparison.Field, kind)
+	if err != nil {
+		return nil, err
+	}
+	comparison.Value = value
+	switch {
+	case value.Kind == NullKind && operator != Eq && operator != Ne:
+		return nil, fmt.Errorf("%s cannot compare %q with null", operator, comparison.Field)
+	case (operator == Contains || operator == StartsWith) && kind != StringKind:
+		return nil, fmt.Errorf("%s needs a string field, %q is a %s", operator, comparison.Field, kind)
+	case (operator == Gt || operator == Ge || operator == Lt || operator == Le) && kind == BoolKind:
+		return nil, fmt.Errorf("%s cannot compare the bool field %q", operator, comparison.Field)
+	}
+	return comparison, nil
+}
+
+// value parses a literal of the kind of field.
+func (p *parser) value(field string, kind Kind) (Value, error) {
+	t := p.advance()
+	mismatch := func() (Value, error) {
+		return Value{}, fmt.Errorf("%q expects a %s value at position %d, got %s", field, kind, t.position, t)
+	}
+	if t.kind == wordToken && strings.EqualFold(t.text, "null") {
+		return Value{Kind: NullKind, Text: "null"}, nil
+	}
+	switch kind {
+	case StringKind:
+		if t.kind == stringToken {
+			return Value{Kind: StringKind, Text: t.text}, nil
+		}
+	case NumberKind:
+		if t.kind == numberToken {
+			number, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return mismatch()
+			}
+			return Value{Kind: NumberKind, Text: t.text, Number: number}, nil
+		}
+	case BoolKind:
+		if t.kind == wordToken && (strings.EqualFold(t.text, "true") || strings.EqualFold(t.text, "false")) {
+			text := strings.ToLower(t.text)
+			return Value{Kind: BoolKind, Text: text, Bool: text == "true"}, nil
+		}
+	case TimeKind:
+		if t.kind == stringToken {
+			for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+				if parsed, err := time.Parse(layout, t.text); err == nil {
+					return Value{Kind: TimeKind, Text: t.text, Time: parsed}, nil
+				}
+			}
+		}
+	}
+	return mismatch()
+}
+
+func sortedFields(fields map[string]Kind) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,204 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+)
+
+// DefaultLimit is the page size of requests setting none, unless Config.DefaultLimit is set.
+const DefaultLimit = 20
+
+// MaxOffset is the largest offset a request may ask for, directly or through
+// its page, so that offsets fit the integer columns of stores and page links
+// cannot overflow.
+const MaxOffset = math.MaxInt32
+
+// Mode is the pagination style of a request.
+type Mode string
+
+const (
+	// OffsetMode pages with ?offset=40&limit=20.
+	OffsetMode Mode = "offset"
+	// PageMode pages with ?page=3&size=20.
+	PageMode Mode = "page"
+	// CursorMode pages with ?cursor=...&limit=20, the cursor being returned by the previous page.
+	CursorMode Mode = "cursor"
+)
+
+// Config holds the settings of a list endpoint. Empty settings use the
+// defaults documented on each field.
+type Config struct {
+	// DefaultLimit is the page size when the request sets none. Defaults to 20.
+	DefaultLimit int
+	// MaxLimit is the largest page size a request may ask for. Defaults to 100.
+	MaxLimit int
+	// Sortable lists the fields the sort param may use. Other fields are rejected.
+	Sortable []string
+	// DefaultSort applies when the request sets no sort, such as "-created_at,name".
+	DefaultSort string
+	// Filterable maps the fields the filter param may use to their kind. Other
+	// fields, and values of another kind, are rejected.
+	Filterable map[string]Kind
+}
+
+// Sort is a field of the sort param.
+type Sort struct {
+	Field string
+	// Desc is set for descending fields, written with a leading "-".
+	Desc bool
+}
+
+// Query is the pagination, sort and filter of a list request.
+type Query struct {
+	// Mode is the pagination style of the request.
+	Mode Mode
+	// Offset is the number of items to skip, computed from Page in PageMode.
+	Offset int
+	// Limit is the page size.
+	Limit int
+	// Page is the 1-based page number in PageMode.
+	Page int
+	// Cursor is the opaque position to continue from in CursorMode.
+	Cursor string
+	// Sort lists the sort fields in order of precedence.
+	Sort []Sort
+	// Filter is the parsed filter expression, or nil when the request has none.
+	Filter Expr
+}
+
+// Parse reads the pagination, sort and filter params of the request:
+//
+//	?offset=40&limit=20 or ?page=3&size=20 or ?cursor=abc&limit=20
+//	&sort=-created_at,name
+//	&filter=status eq 'active' and (age ge 18 or verified eq true)
+//
+// It returns a BadRequestException when a param is invalid, which handlers can
+// return as-is.
+func Parse(c *gin.Context, config Config) (*Query, error) {
+	if config.DefaultLimit <= 0 {
+		config.DefaultLimit = DefaultLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = 100
+	}
+	if config.DefaultLimit > config.MaxLimit {
+		config.DefaultLimit = config.MaxLimit
+	}
+
+	q := &Query{Mode: OffsetMode, Limit: config.DefaultLimit}
+	var err error
+
+	limitParam := "limit"
+	if _, hasSize := c.GetQuery("size"); hasSize {
+		limitParam = "size"
+	}
+	if q.Limit, err = intParam(c, limitParam, config.DefaultLimit, 1); err != nil {
+		return nil, err
+	}
+	if q.Limit > config.MaxLimit {
+		return nil, invalid(limitParam, fmt.Sprintf("must be at most %d", config.MaxLimit))
+	}
+
+	cursor, hasCursor := c.GetQuery("cursor")
+	_, hasPage := c.GetQuery("page")
+	_, hasOffset := c.GetQuery("offset")
+	switch {
+	case hasCursor && (hasPage || hasOffset), hasPage && hasOffset:
+		return nil, exception.BadRequestException("Use only one of the cursor, page and offset params")
+	case hasCursor:
+		q.Mode, q.Cursor = CursorMode, cursor
+	case hasPage:
+		q.Mode = PageMode
+		if q.Page, err = intParam(c, "page", 1, 1); err != nil {
+			return nil, err
+		}
+		if maxPage := MaxOffset/q.Limit + 1; q.Page > maxPage {
+			return nil, invalid("page", fmt.Sprintf("must be at most %d", maxPage))
+		}
+		q.Offset = (q.Page - 1) * q.Limit
+	default:
+		if q.Offset, err = intParam(c, "offset", 0, 0); err != nil {
+			return nil, err
+		}
+		if q.Offset > MaxOffset {
+			return nil, invalid("offset", fmt.Sprintf("must be at most %d", MaxOffset))
+		}
+		q.Page = q.Offset/q.Limit + 1
+	}
+
+	sortParam := c.Query("sort")
+	if sortParam == "" {
+		sortParam = config.DefaultSort
+	}
+	if q.Sort, err = parseSort(sortParam, config.Sortable); err != nil {
+		return nil, err
+	}
+
+	if filter := c.Query("filter"); strings.TrimSpace(filter) != "" {
+		if q.Filter, err = ParseFilter(filter, config.Filterable); err != nil {
+			return nil, exception.BadRequestException(fmt.Sprintf("Invalid query param %q: %v", "filter", err))
+		}
+	}
+	return q, nil
+}
+
+// intParam reads an integer query param of at least minimum, or fallback when it is absent.
+func intParam(c *gin.Context, name string, fallback int, minimum int) (int, error) {
+	raw, exists := c.GetQuery(name)
+	if !exists || raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, invalid(name, "must be an integer")
+	}
+	if value < minimum {
+		return 0, invalid(name, fmt.Sprintf("must be at least %d", minimum))
+	}
+	return value, nil
+}
+
+// parseSort parses a sort param such as "-created_at,name" against the allowed fields.
+func parseSort(param string, sortable []string) ([]Sort, error) {
+	var fields []Sort
+	for _, part := range strings.Split(param, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := Sort{Field: strings.TrimLeft(part, "+-"), Desc: strings.HasPrefix(part, "-")}
+		if name, direction, found := strings.Cut(field.Field, ":"); found {
+			field.Field = name
+			switch strings.ToLower(direction) {
+			case "asc":
+			case "desc":
+				field.Desc = true
+			default:
+				return nil, invalid("sort", fmt.Sprintf("direction of %q must be asc or desc", name))
+			}
+		}
+		if !contains(sortable, field.Field) {
+			return nil, invalid("sort", fmt.Sprintf("cannot sort by %q, sortable fields: %s", field.Field, strings.Join(sortable, ", ")))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func invalid(name string, reason string) exception.HttpExceptionResponse {
+	return exception.BadRequestException(fmt.Sprintf("Invalid query param %q: %s", name, reason))
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,16 @@
 package routix
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/metadata"
+	"github.com/l1ttps/routix/view"
+)
 
 const (
-	RENDER string = "ROUTIX_RENDER"
+	RENDER     string = "ROUTIX_RENDER"
+	ERROR_VIEW string = "ROUTIX_ERROR_VIEW"
 )
 
 type RenderParams struct {
@@ -12,10 +19,113 @@ type RenderParams struct {
 
 // Render generates a gin.HandlerFunc that sets the RENDER context key to the provided path.
 //
+// The handler's return value is the data of the view. Clients preferring
+// another media type than HTML in their Accept header receive the data in that
+// media type instead, so one route serves both browsers and API clients.
+//
 // path: The path to be set in the RENDER context key.
 // Return: A gin.HandlerFunc that sets the RENDER context key.
 func Render(path string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Set(RENDER, path)
+	return metadata.Set(RENDER, path)
+}
+
+// ErrorView sets the view rendering the exceptions returned by the routes it
+// is applied to, overriding ServerConfig.ErrorViews.
+func ErrorView(path string) gin.HandlerFunc {
+	return metadata.Set(ERROR_VIEW, path)
+}
+
+// ViewResponse renders a view chosen by the handler at request time.
+type ViewResponse struct {
+	name   string
+	data   any
+	status int
+}
+
+// View renders the view name with data, taking precedence over the route's Render.
+//
+//	if user.IsAdmin {
+//		return routix.View("dashboard/admin.html", data)
+//	}
+//	return routix.View("dashboard/user.html", data)
+func View(name string, data any) *ViewResponse {
+	return &ViewResponse{name: name, data: data}
+}
+
+// Status sets the status code of the response. Defaults to the route's HttpCode, or 200.
+func (v *ViewResponse) Status(status int) *ViewResponse {
+	v.status = status
+	return v
+}
+
+// Respond renders the view. It implements Responder.
+func (v *ViewResponse) Respond(c *gin.Context) {
+	status := v.status
+	if status == 0 {
+		status = DefaultStatus(c)
+	}
+	renderView(c, status, v.name, v.data)
+}
+
+// renderView renders the view name, or negotiates data when the client prefers another media type.
+func renderView(c *gin.Context, status int, name string, data any) {
+	if !acceptsHTML(c, true) {
+		respondWithBody(c, status, data)
+		return
+	}
+	if !appOf(c).renderEnabled {
+		respondException(c, exception.InternalServerErrorException("Cannot render view "+name+": views are not enabled"))
+		return
+	}
+	c.HTML(status, name, view.Merge(c, data))
+}
+
+// RespondError is the default error responder. It writes an exception with the
+// error view of its status when the client prefers HTML, and in the negotiated
+// media type otherwise. Custom responders can delegate to it.
+//
+// The error view receives status, message, exception (such as "NotFoundException")
+// and path, along with the values exposed to views.
+func RespondError(c *gin.Context, httpException exception.HttpExceptionResponse) {
+	_, viewRoute := c.Get(RENDER)
+	name := errorViewOf(c, httpException.Status)
+	if name == "" || !appOf(c).renderEnabled || !acceptsHTML(c, viewRoute) {
+		respondException(c, httpException)
+		return
+	}
+
+	c.Set(exception.EXCEPTION, httpException)
+	c.HTML(httpException.Status, name, view.Merge(c, gin.H{
+		"status":    httpException.Status,
+		"message":   httpException.Message,
+		"exception": httpException.Name(),
+		"path":      c.Request.URL.Path,
+	}))
+}
+
+// errorViewOf returns the view rendering exceptions of status: the route's
+// ErrorView, the ServerConfig.ErrorViews entry of status, or its 0 entry.
+func errorViewOf(c *gin.Context, status int) string {
+	if name, exists := c.Get(ERROR_VIEW); exists {
+		return name.(string)
+	}
+	errorViews := appOf(c).errorViews
+	if name, exists := errorViews[status]; exists {
+		return name
+	}
+	return errorViews[0]
+}
+
+// acceptsHTML reports whether the client prefers HTML over the media types the
+// route produces. preferred puts HTML first, so it wins ties and requests
+// without an Accept header; otherwise the client must rank it higher.
+func acceptsHTML(c *gin.Context, preferred bool) bool {
+	offered := offeredMediaTypes(c)
+	if preferred {
+		offered = append([]string{binding.MIMEHTML}, offered...)
+	} else {
+		offered = append(offered, binding.MIMEHTML)
 	}
+	ranked := rankMediaTypes(offered, c.GetHeader("Accept"))
+	return len(ranked) > 0 && ranked[0] == binding.MIMEHTML
 }
@@ -0,0 +1,146 @@
+package routix
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/metadata"
+)
+
+const (
+	HTTP_CODE string = "ROUTIX_HTTP_CODE"
+)
+
+// Responder is implemented by handler return values that write the whole response themselves.
+//
+// PipeResponse hands the gin.Context to Respond instead of serializing the value.
+type Responder interface {
+	Respond(c *gin.Context)
+}
+
+// Response describes a complete response returned by a handler: status, headers, cookies and body.
+//
+// Build it with Respond, Created or NoContent and chain the setters:
+//
+//	return routix.Created(user).Header("Location", "/users/"+user.ID)
+type Response struct {
+	status  int
+	headers http.Header
+	cookies []*http.Cookie
+	body    any
+}
+
+// Respond creates a Response with the given body.
+//
+// The status defaults to the route's HttpCode, or 200 when the route has none.
+func Respond(body any) *Response {
+	return &Response{
+		headers: http.Header{},
+		body:    body,
+	}
+}
+
+// Created creates a Response with status 201 and the given body.
+func Created(body any) *Response {
+	return Respond(body).Status(http.StatusCreated)
+}
+
+// NoContent creates a Response with status 204 and no body.
+func NoContent() *Response {
+	return Respond(nil).Status(http.StatusNoContent)
+}
+
+// Status sets the status code of the response.
+func (r *Response) Status(status int) *Response {
+	r.status = status
+	return r
+}
+
+// Header sets a response header, replacing any existing values.
+func (r *Response) Header(key, value string) *Response {
+	r.headers.Set(key, value)
+	return r
+}
+
+// AddHeader appends a value to a response header.
+func (r *Response) AddHeader(key, value string) *Response {
+	r.headers.Add(key, value)
+	return r
+}
+
+// Cookie adds a Set-Cookie header to the response.
+func (r *Response) Cookie(cookie *http.Cookie) *Response {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// Body sets the body of the response.
+func (r *Response) Body(body any) *Response {
+	r.body = body
+	return r
+}
+
+// Respond writes the response. It implements Responder.
+func (r *Response) Respond(c *gin.Context) {
+	for key, values := range r.headers {
+		for _, value := range values {
+			c.Writer.Header().Add(key, value)
+		}
+	}
+	for _, cookie := range r.cookies {
+		http.SetCookie(c.Writer, cookie)
+	}
+
+	status := r.status
+	if status == 0 {
+		status = DefaultStatus(c)
+	}
+
+	if r.body == nil {
+		c.Status(status)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	respondWithBody(c, status, r.body)
+}
+
+// HttpCode sets the default status code of a route.
+//
+// Handlers returning a plain value respond with this status instead of 200. A
+// 204 or 304 status drops the body. The code is recorded as route metadata and
+// is available to documentation generators through Routes.
+func HttpCode(status int) gin.HandlerFunc {
+	return metadata.Set(HTTP_CODE, status)
+}
+
+// DefaultStatus returns the status set by the route's HttpCode, or 200 when it has none.
+func DefaultStatus(c *gin.Context) int {
+	if status, exists := c.Get(HTTP_CODE); exists {
+		return status.(int)
+	}
+	return http.StatusOK
+}
+
+// respondWithBody negotiates body with status, or writes only the status when
+// the status does not allow a body.
+func respondWithBody(c *gin.Context, status int, body any) {
+	if !bodyAllowedForStatus(status) {
+		c.Status(status)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	respond(c, status, body)
+}
+
+// bodyAllowedForStatus reports whether a response with status may carry a body (RFC 9110).
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
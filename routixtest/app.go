@@ -0,0 +1,177 @@
+// Package routixtest runs routix apps in-process for tests.
+//
+//	func TestGetUser(t *testing.T) {
+//		app := routixtest.New(t, routix.ServerConfig{
+//			Controllers: []routix.ControllerType{controllers.UserController},
+//		}, routixtest.Provide("users", fakeUsers))
+//
+//		app.Get("/users/1").BearerToken("token").Do().
+//			Status(http.StatusOK).
+//			JSONPath("$.name", "Ada")
+//
+//		app.Get("/users/404").Do().Exception(http.StatusNotFound)
+//	}
+package routixtest
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix"
+	"github.com/l1ttps/routix/config"
+	"github.com/l1ttps/routix/contract"
+	"github.com/l1ttps/routix/guard"
+	"github.com/l1ttps/routix/interceptor"
+)
+
+// App is a routix server built for a test. Every app has its own routes and
+// request-time state, so tests using different apps may run in parallel.
+type App struct {
+	t         testing.TB
+	engine    *gin.Engine
+	snapshots *snapshotCounter
+	contract  *contractCheck
+}
+
+type contractCheck struct {
+	document *contract.Document
+	options  contract.Options
+}
+
+// Option customizes the app built by New.
+type Option func(s *settings)
+
+type settings struct {
+	values      map[string]any
+	configs     []func() (restore func())
+	middlewares []gin.HandlerFunc
+	contract    *contractCheck
+}
+
+// Provide sets key to value in the context of every request, before the
+// controllers run, so handlers and guards reading their dependencies with
+// ctx.MustGet(key) receive test doubles.
+func Provide(key string, value any) Option {
+	return func(s *settings) {
+		if s.values == nil {
+			s.values = map[string]any{}
+		}
+		s.values[key] = value
+	}
+}
+
+// OverrideGuard makes every guard.UseGuard of the app call replacement instead
+// of original, such as a guard built by Authenticate in place of the JWT guard.
+func OverrideGuard(original func(c *gin.Context) bool, replacement func(c *gin.Context) bool) Option {
+	return func(s *settings) {
+		s.middlewares = append(s.middlewares, guard.Override(original, replacement))
+	}
+}
+
+// DisableGuard makes every guard.UseGuard of the app skip original.
+func DisableGuard(original func(c *gin.Context) bool) Option {
+	return OverrideGuard(original, nil)
+}
+
+// OverrideInterceptor makes every interceptor.UseInterceptor of the app call
+// replacement instead of original.
+func OverrideInterceptor(original func(c *interceptor.InterceptorContext) func(), replacement func(c *interceptor.InterceptorContext) func()) Option {
+	return func(s *settings) {
+		s.middlewares = append(s.middlewares, interceptor.Override(original, replacement))
+	}
+}
+
+// DisableInterceptor makes every interceptor.UseInterceptor of the app skip original.
+func DisableInterceptor(original func(c *interceptor.InterceptorContext) func()) Option {
+	return OverrideInterceptor(original, nil)
+}
+
+// Authenticate returns a guard function that sets key to principal in the
+// context and allows the request, to replace guards checking real credentials:
+//
+//	routixtest.OverrideGuard(guards.JwtGuard, routixtest.Authenticate("user", testUser))
+func Authenticate(key string, principal any) func(c *gin.Context) bool {
+	return func(c *gin.Context) bool {
+		c.Set(key, principal)
+		return true
+	}
+}
+
+// WithContract checks every response of the app against document, as
+// Response.Contract does, so a test suite covers the whole document.
+func WithContract(document *contract.Document, options ...contract.Options) Option {
+	return func(s *settings) {
+		s.contract = &contractCheck{document: document}
+		if len(options) > 0 {
+			s.contract.options = options[0]
+		}
+	}
+}
+
+// WithConfig makes value the configuration returned by config.Get[T] until the
+// test ends. The configuration registry is shared by the whole process, so
+// parallel tests must not override the same type with different values.
+func WithConfig[T any](value *T) Option {
+	return func(s *settings) {
+		s.configs = append(s.configs, func() func() {
+			previous := config.Get[T]()
+			config.Set(value)
+			return func() { config.Set(previous) }
+		})
+	}
+}
+
+// New builds the app described by serverConfig. The app is closed when the test ends.
+func New(t testing.TB, serverConfig routix.ServerConfig, options ...Option) *App {
+	t.Helper()
+	s := &settings{}
+	for _, option := range options {
+		option(s)
+	}
+
+	for _, apply := range s.configs {
+		t.Cleanup(apply())
+	}
+	if len(s.values) > 0 {
+		values := s.values
+		provide := func(c *gin.Context) {
+			for key, value := range values {
+				c.Set(key, value)
+			}
+		}
+		s.middlewares = append([]gin.HandlerFunc{provide}, s.middlewares...)
+	}
+	if len(s.middlewares) > 0 {
+		serverConfig.Middlewares = append(s.middlewares, serverConfig.Middlewares...)
+	}
+
+	engine := routix.CreateServer(serverConfig)
+	t.Cleanup(func() { routix.CloseServer(engine) })
+	return &App{t: t, engine: engine, snapshots: &snapshotCounter{counts: map[string]int{}}, contract: s.contract}
+}
+
+// WithT returns the app reporting to t, for subtests sharing an app.
+func (a *App) WithT(t testing.TB) *App {
+	return &App{t: t, engine: a.engine, snapshots: a.snapshots, contract: a.contract}
+}
+
+// Engine returns the gin engine of the app.
+func (a *App) Engine() *gin.Engine {
+	return a.engine
+}
+
+// Routes returns the routes registered on the app.
+func (a *App) Routes() []routix.RouteInfo {
+	return routix.RoutesOf(a.engine)
+}
+
+// Guards returns the guard functions protecting each route of the app, keyed
+// by method and path such as "GET /users/:id". Routes without guards are listed
+// with none, so tests can assert that every route is protected.
+func (a *App) Guards() map[string][]string {
+	guards := map[string][]string{}
+	for _, route := range a.Routes() {
+		guards[string(route.Method)+" "+route.Path] = route.Guards
+	}
+	return guards
+}
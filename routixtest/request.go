@@ -0,0 +1,133 @@
+package routixtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// Request builds a request to the app. Send it with Do.
+type Request struct {
+	app     *App
+	method  string
+	path    string
+	header  http.Header
+	query   url.Values
+	body    io.Reader
+	cookies []*http.Cookie
+	err     error
+}
+
+// Request starts a request with any method.
+func (a *App) Request(method string, path string) *Request {
+	return &Request{app: a, method: method, path: path, header: http.Header{}, query: url.Values{}}
+}
+
+// Get starts a GET request.
+func (a *App) Get(path string) *Request { return a.Request(http.MethodGet, path) }
+
+// Post starts a POST request.
+func (a *App) Post(path string) *Request { return a.Request(http.MethodPost, path) }
+
+// Put starts a PUT request.
+func (a *App) Put(path string) *Request { return a.Request(http.MethodPut, path) }
+
+// Patch starts a PATCH request.
+func (a *App) Patch(path string) *Request { return a.Request(http.MethodPatch, path) }
+
+// Delete starts a DELETE request.
+func (a *App) Delete(path string) *Request { return a.Request(http.MethodDelete, path) }
+
+// Header sets a request header.
+func (r *Request) Header(name string, value string) *Request {
+	r.header.Set(name, value)
+	return r
+}
+
+// Accept sets the Accept header.
+func (r *Request) Accept(mediaType string) *Request {
+	return r.Header("Accept", mediaType)
+}
+
+// Query adds a query parameter.
+func (r *Request) Query(name string, value string) *Request {
+	r.query.Add(name, value)
+	return r
+}
+
+// Cookie adds a cookie.
+func (r *Request) Cookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// BearerToken sets the Authorization header to a bearer token.
+func (r *Request) BearerToken(token string) *Request {
+	return r.Header("Authorization", "Bearer "+token)
+}
+
+// BasicAuth sets the Authorization header to basic credentials.
+func (r *Request) BasicAuth(username string, password string) *Request {
+	request := http.Request{Header: http.Header{}}
+	request.SetBasicAuth(username, password)
+	return r.Header("Authorization", request.Header.Get("Authorization"))
+}
+
+// JSON sends body encoded as JSON.
+func (r *Request) JSON(body any) *Request {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.Body("application/json", encoded)
+}
+
+// Form sends values URL-encoded, as a browser form does.
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body("application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// Body sends raw content with its media type.
+func (r *Request) Body(contentType string, content []byte) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = bytes.NewReader(content)
+	return r
+}
+
+// Do sends the request to the app and returns its response.
+func (r *Request) Do() *Response {
+	t := r.app.t
+	t.Helper()
+	if r.err != nil {
+		t.Fatalf("routixtest: cannot build %s %s: %v", r.method, r.path, r.err)
+	}
+
+	target := r.path
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+	request := httptest.NewRequest(r.method, target, r.body)
+	for name, values := range r.header {
+		request.Header[name] = values
+	}
+	for _, cookie := range r.cookies {
+		request.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	r.app.engine.ServeHTTP(recorder, request)
+	response := &Response{app: r.app, t: t, request: request, Recorder: recorder}
+	if check := r.app.contract; check != nil {
+		response.Contract(check.document, check.options)
+	}
+	return response
+}
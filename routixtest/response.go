@@ -0,0 +1,195 @@
+package routixtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/l1ttps/routix/contract"
+)
+
+// Response is the response of a request. Its assertions report failures with
+// t.Errorf and return the response, so they chain.
+type Response struct {
+	// Recorder holds the raw response.
+	Recorder *httptest.ResponseRecorder
+
+	app     *App
+	t       testing.TB
+	request *http.Request
+	decoded any
+	jsonErr error
+	parsed  bool
+}
+
+// Body returns the response body.
+func (r *Response) Body() string {
+	return r.Recorder.Body.String()
+}
+
+// Cookies returns the cookies set by the response.
+func (r *Response) Cookies() []*http.Cookie {
+	return r.Recorder.Result().Cookies()
+}
+
+// Status asserts the status code.
+func (r *Response) Status(expected int) *Response {
+	r.t.Helper()
+	if actual := r.Recorder.Code; actual != expected {
+		r.t.Errorf("%s %s: expected status %d, got %d\n%s", r.request.Method, r.request.URL, expected, actual, r.Body())
+	}
+	return r
+}
+
+// Header asserts the value of a response header.
+func (r *Response) Header(name string, expected string) *Response {
+	r.t.Helper()
+	if actual := r.Recorder.Header().Get(name); actual != expected {
+		r.t.Errorf("%s %s: expected header %s %q, got %q", r.request.Method, r.request.URL, name, expected, actual)
+	}
+	return r
+}
+
+// HeaderContains asserts that a response header contains substring.
+func (r *Response) HeaderContains(name string, substring string) *Response {
+	r.t.Helper()
+	if actual := r.Recorder.Header().Get(name); !strings.Contains(actual, substring) {
+		r.t.Errorf("%s %s: expected header %s to contain %q, got %q", r.request.Method, r.request.URL, name, substring, actual)
+	}
+	return r
+}
+
+// Decode decodes the JSON body into target.
+func (r *Response) Decode(target any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), target); err != nil {
+		r.t.Errorf("%s %s: cannot decode body: %v\n%s", r.request.Method, r.request.URL, err, r.Body())
+	}
+	return r
+}
+
+// JSONPath asserts the value at path in the JSON body. Paths look like
+// "$.items[0].name" or "items.0.name". expected is compared after a JSON
+// round trip, so 42 matches the number 42 and structs match objects.
+func (r *Response) JSONPath(path string, expected any) *Response {
+	r.t.Helper()
+	actual, err := r.lookup(path)
+	if err != nil {
+		r.t.Errorf("%s %s: %v\n%s", r.request.Method, r.request.URL, err, r.Body())
+		return r
+	}
+	normalized, err := normalizeJSON(expected)
+	if err != nil {
+		r.t.Errorf("routixtest: cannot encode expected value of %s: %v", path, err)
+		return r
+	}
+	if !reflect.DeepEqual(actual, normalized) {
+		r.t.Errorf("%s %s: expected %s to be %s, got %s", r.request.Method, r.request.URL, path, formatJSON(normalized), formatJSON(actual))
+	}
+	return r
+}
+
+// JSONPathExists asserts that path is present in the JSON body.
+func (r *Response) JSONPathExists(path string) *Response {
+	r.t.Helper()
+	if _, err := r.lookup(path); err != nil {
+		r.t.Errorf("%s %s: %v\n%s", r.request.Method, r.request.URL, err, r.Body())
+	}
+	return r
+}
+
+// Exception asserts that the response is the exception routix writes for an
+// HttpExceptionResponse: the status code, and a JSON body with the same
+// status and a message, equal to message when given.
+func (r *Response) Exception(status int, message ...string) *Response {
+	r.t.Helper()
+	r.Status(status)
+	r.JSONPath("$.status", status)
+	if len(message) > 0 {
+		r.JSONPath("$.message", message[0])
+	} else if actual, err := r.lookup("$.message"); err != nil {
+		r.t.Errorf("%s %s: exception has no message\n%s", r.request.Method, r.request.URL, r.Body())
+	} else if _, ok := actual.(string); !ok {
+		r.t.Errorf("%s %s: exception message is not a string: %s", r.request.Method, r.request.URL, formatJSON(actual))
+	}
+	return r
+}
+
+// Contract asserts that the response follows the operation of document
+// matching the request, and reports each difference found.
+func (r *Response) Contract(document *contract.Document, options ...contract.Options) *Response {
+	r.t.Helper()
+	var settings contract.Options
+	if len(options) > 0 {
+		settings = options[0]
+	}
+	diagnostics := document.Validate(r.request.Method, r.request.URL.Path, r.Recorder.Code, r.Recorder.Header(), r.Recorder.Body.Bytes(), settings)
+	for _, diagnostic := range diagnostics {
+		r.t.Errorf("%s %s: contract drift: %s", r.request.Method, r.request.URL, diagnostic)
+	}
+	return r
+}
+
+// lookup returns the value at path in the decoded body.
+func (r *Response) lookup(path string) (any, error) {
+	if !r.parsed {
+		r.parsed = true
+		r.jsonErr = json.Unmarshal(r.Recorder.Body.Bytes(), &r.decoded)
+	}
+	if r.jsonErr != nil {
+		return nil, fmt.Errorf("body is not JSON: %v", r.jsonErr)
+	}
+
+	current := r.decoded
+	for _, segment := range splitPath(path) {
+		switch node := current.(type) {
+		case map[string]any:
+			value, exists := node[segment]
+			if !exists {
+				return nil, fmt.Errorf("%s: no field %q", path, segment)
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("%s: no index %q in array of %d", path, segment, len(node))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%s: cannot descend into %s at %q", path, formatJSON(node), segment)
+		}
+	}
+	return current, nil
+}
+
+// splitPath turns "$.items[0].name" into ["items", "0", "name"].
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+func normalizeJSON(value any) (any, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	return normalized, json.Unmarshal(encoded, &normalized)
+}
+
+func formatJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,103 @@
+package routixtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SnapshotDir is the directory snapshots are kept in, relative to the package under test.
+var SnapshotDir = filepath.Join("testdata", "snapshots")
+
+// UpdateSnapshotsEnv is the environment variable that rewrites snapshots instead of comparing them.
+const UpdateSnapshotsEnv = "ROUTIX_UPDATE_SNAPSHOTS"
+
+// snapshotCounter numbers the unnamed snapshots of each test.
+type snapshotCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *snapshotCounter) next(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[key]++
+	return c.counts[key]
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// MatchSnapshot compares the status, content type and body of the response
+// with the snapshot stored for the test, creating it on first run. JSON bodies
+// are indented so diffs stay readable. Run the tests with
+// ROUTIX_UPDATE_SNAPSHOTS=1 to accept changes.
+//
+// When the CI environment variable is set, as by most CI services, a missing
+// snapshot fails the test instead of being created, unless
+// ROUTIX_UPDATE_SNAPSHOTS is set too.
+//
+// Snapshots are named after the test, then name or a counter when the test
+// takes several.
+func (r *Response) MatchSnapshot(name ...string) *Response {
+	r.t.Helper()
+	key := r.t.Name()
+	if len(name) > 0 {
+		key += "_" + name[0]
+	} else if count := r.app.snapshots.next(key); count > 1 {
+		key += fmt.Sprintf("_%d", count)
+	}
+	file := filepath.Join(SnapshotDir, unsafeFileChars.ReplaceAllString(key, "_")+".snap")
+	actual := r.snapshot()
+
+	expected, err := os.ReadFile(file)
+	update := os.Getenv(UpdateSnapshotsEnv) != ""
+	if os.IsNotExist(err) && !update && os.Getenv("CI") != "" {
+		r.t.Fatalf("routixtest: snapshot %s is missing (set %s=1 to create it)", file, UpdateSnapshotsEnv)
+	}
+	if os.IsNotExist(err) || update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			r.t.Fatalf("routixtest: cannot create %s: %v", filepath.Dir(file), err)
+		}
+		if err := os.WriteFile(file, []byte(actual), 0o644); err != nil {
+			r.t.Fatalf("routixtest: cannot write snapshot %s: %v", file, err)
+		}
+		r.t.Logf("routixtest: wrote snapshot %s", file)
+		return r
+	}
+	if err != nil {
+		r.t.Fatalf("routixtest: cannot read snapshot %s: %v", file, err)
+	}
+
+	if string(expected) != actual {
+		r.t.Errorf("%s %s: response does not match snapshot %s (set %s=1 to update)\n--- snapshot\n%s\n--- actual\n%s",
+			r.request.Method, r.request.URL, file, UpdateSnapshotsEnv, expected, actual)
+	}
+	return r
+}
+
+// snapshot renders the response as stored in snapshot files.
+func (r *Response) snapshot() string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "%s %s\n", r.request.Method, r.request.URL.RequestURI())
+	fmt.Fprintf(&content, "Status: %d\n", r.Recorder.Code)
+	if contentType := r.Recorder.Header().Get("Content-Type"); contentType != "" {
+		fmt.Fprintf(&content, "Content-Type: %s\n", contentType)
+	}
+	content.WriteString("\n")
+
+	body := r.Recorder.Body.Bytes()
+	var indented bytes.Buffer
+	if json.Valid(body) && json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	content.Write(body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		content.WriteString("\n")
+	}
+	return content.String()
+}
@@ -0,0 +1,177 @@
+package security
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/logger"
+)
+
+// Report is a CSP violation report, sent either with report-uri
+// (application/csp-report) or with the Reporting API (application/reports+json).
+type Report struct {
+	DocumentURI        string `json:"documentURI"`
+	Referrer           string `json:"referrer,omitempty"`
+	BlockedURI         string `json:"blockedURI"`
+	EffectiveDirective string `json:"effectiveDirective"`
+	OriginalPolicy     string `json:"originalPolicy"`
+	Disposition        string `json:"disposition"`
+	SourceFile         string `json:"sourceFile,omitempty"`
+	LineNumber         int    `json:"lineNumber,omitempty"`
+	ColumnNumber       int    `json:"columnNumber,omitempty"`
+	StatusCode         int    `json:"statusCode,omitempty"`
+	Sample             string `json:"sample,omitempty"`
+}
+
+// legacyReport is the body posted by report-uri.
+type legacyReport struct {
+	Body struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		StatusCode         int    `json:"status-code"`
+		ScriptSample       string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is one entry of the body posted by the Reporting API.
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		Referrer           string `json:"referrer"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		ColumnNumber       int    `json:"columnNumber"`
+		StatusCode         int    `json:"statusCode"`
+		Sample             string `json:"sample"`
+	} `json:"body"`
+}
+
+// reportLogInterval is the period over which logged reports are aggregated.
+const reportLogInterval = time.Minute
+
+// reportLogLimit is the number of distinct violations logged per period.
+const reportLogLimit = 10
+
+// ReportHandler is the handler of the report-collection endpoint. It passes
+// every report of the request body to OnReport and answers 204 No Content.
+// Bodies larger than MaxReportSize are answered with 413.
+func (m *Module) ReportHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, m.config.MaxReportSize)
+	body, err := c.GetRawData()
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	reports, err := parseReports(body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	for _, report := range reports {
+		m.config.OnReport(c, report)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// parseReports reads a report-uri body or a Reporting API batch.
+func parseReports(body []byte) ([]Report, error) {
+	var batch []reportingAPIReport
+	if err := json.Unmarshal(body, &batch); err == nil {
+		reports := []Report{}
+		for _, entry := range batch {
+			if entry.Type != "csp-violation" {
+				continue
+			}
+			reports = append(reports, Report{
+				DocumentURI:        entry.Body.DocumentURL,
+				Referrer:           entry.Body.Referrer,
+				BlockedURI:         entry.Body.BlockedURL,
+				EffectiveDirective: entry.Body.EffectiveDirective,
+				OriginalPolicy:     entry.Body.OriginalPolicy,
+				Disposition:        entry.Body.Disposition,
+				SourceFile:         entry.Body.SourceFile,
+				LineNumber:         entry.Body.LineNumber,
+				ColumnNumber:       entry.Body.ColumnNumber,
+				StatusCode:         entry.Body.StatusCode,
+				Sample:             entry.Body.Sample,
+			})
+		}
+		return reports, nil
+	}
+
+	var legacy legacyReport
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, err
+	}
+	directive := legacy.Body.EffectiveDirective
+	if directive == "" {
+		directive = legacy.Body.ViolatedDirective
+	}
+	return []Report{{
+		DocumentURI:        legacy.Body.DocumentURI,
+		Referrer:           legacy.Body.Referrer,
+		BlockedURI:         legacy.Body.BlockedURI,
+		EffectiveDirective: directive,
+		OriginalPolicy:     legacy.Body.OriginalPolicy,
+		Disposition:        legacy.Body.Disposition,
+		SourceFile:         legacy.Body.SourceFile,
+		LineNumber:         legacy.Body.LineNumber,
+		ColumnNumber:       legacy.Body.ColumnNumber,
+		StatusCode:         legacy.Body.StatusCode,
+		Sample:             legacy.Body.ScriptSample,
+	}}, nil
+}
+
+// reportLog logs violation reports, so that a page or a client sending many
+// reports cannot flood the logs. Within a period, a violation of the same
+// directive and blocked URI is logged once, and at most reportLogLimit
+// distinct violations are logged. The number of reports left out is logged
+// with the first report of the next period.
+type reportLog struct {
+	mu         sync.Mutex
+	period     time.Time
+	seen       map[string]bool
+	suppressed int
+}
+
+func (l *reportLog) log(c *gin.Context, report Report) {
+	log := logger.Logger("Security")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now := time.Now(); now.Sub(l.period) >= reportLogInterval {
+		if l.suppressed > 0 {
+			log.Warning(fmt.Sprintf("%d more CSP violation reports were not logged", l.suppressed))
+		}
+		l.period, l.seen, l.suppressed = now, map[string]bool{}, 0
+	}
+	key := report.EffectiveDirective + " " + report.BlockedURI
+	if l.seen[key] || len(l.seen) >= reportLogLimit {
+		l.suppressed++
+		return
+	}
+	l.seen[key] = true
+	log.Warning(fmt.Sprintf("CSP violation on %s: %s blocked %s", report.DocumentURI, report.EffectiveDirective, report.BlockedURI))
+}
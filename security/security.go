@@ -0,0 +1,182 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"mime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/view"
+)
+
+// NONCE is the context key holding the CSP nonce generated for the request.
+const NONCE string = "ROUTIX_CSP_NONCE"
+
+// Disabled omits a header whose setting defaults to a value.
+const Disabled string = "-"
+
+// Config holds the settings of the security headers module. Empty settings
+// use the defaults documented on each field.
+type Config struct {
+	// HSTSMaxAge is the max-age of Strict-Transport-Security. Defaults to 180
+	// days; a negative value omits the header.
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubDomains adds includeSubDomains to Strict-Transport-Security.
+	HSTSIncludeSubDomains bool
+	// HSTSPreload adds preload to Strict-Transport-Security.
+	HSTSPreload bool
+	// DisableNoSniff omits X-Content-Type-Options: nosniff.
+	DisableNoSniff bool
+	// FrameOptions is the X-Frame-Options value. Defaults to "DENY".
+	FrameOptions string
+	// ReferrerPolicy is the Referrer-Policy value. Defaults to "no-referrer".
+	ReferrerPolicy string
+	// CrossOriginOpenerPolicy is the Cross-Origin-Opener-Policy value. Defaults to "same-origin".
+	CrossOriginOpenerPolicy string
+	// CrossOriginResourcePolicy is the Cross-Origin-Resource-Policy value. Defaults to "same-origin".
+	CrossOriginResourcePolicy string
+	// CSP is the Content-Security-Policy sent with HTML responses such as rendered views.
+	CSP *CSP
+	// ReportPath mounts an endpoint collecting CSP violation reports, relative
+	// to the server root rather than PathRoot. The policy reports to it unless
+	// it already has a report-uri.
+	ReportPath string
+	// OnReport receives the collected violation reports. Defaults to logging
+	// them, at most once per minute for the same directive and blocked URI,
+	// and for up to 10 distinct violations per minute.
+	OnReport func(c *gin.Context, report Report)
+	// MaxReportSize is the largest size of a report request body, in bytes.
+	// Larger bodies are answered with 413 Request Entity Too Large. Defaults to 8 KiB.
+	MaxReportSize int64
+}
+
+// Module adds security headers to every response.
+type Module struct {
+	config  Config
+	headers map[string]string
+}
+
+// New creates a security headers module. Mount it with ServerConfig.Security.
+func New(config Config) *Module {
+	if config.HSTSMaxAge == 0 {
+		config.HSTSMaxAge = 180 * 24 * time.Hour
+	}
+	if config.OnReport == nil {
+		config.OnReport = (&reportLog{}).log
+	}
+	if config.MaxReportSize <= 0 {
+		config.MaxReportSize = 8 << 10
+	}
+	if config.CSP != nil && config.ReportPath != "" && !config.CSP.hasDirective("report-uri") {
+		config.CSP.ReportURI(config.ReportPath)
+	}
+
+	headers := map[string]string{}
+	if config.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(int(config.HSTSMaxAge.Seconds()))
+		if config.HSTSIncludeSubDomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+	if !config.DisableNoSniff {
+		headers["X-Content-Type-Options"] = "nosniff"
+	}
+	setHeader(headers, "X-Frame-Options", config.FrameOptions, "DENY")
+	setHeader(headers, "Referrer-Policy", config.ReferrerPolicy, "no-referrer")
+	setHeader(headers, "Cross-Origin-Opener-Policy", config.CrossOriginOpenerPolicy, "same-origin")
+	setHeader(headers, "Cross-Origin-Resource-Policy", config.CrossOriginResourcePolicy, "same-origin")
+
+	return &Module{config: config, headers: headers}
+}
+
+// ReportPath returns the path of the report-collection endpoint, empty when it is not mounted.
+func (m *Module) ReportPath() string {
+	return m.config.ReportPath
+}
+
+// Middleware adds the security headers and, when the policy uses the Nonce
+// source, generates the request's nonce and exposes it to templates as cspNonce.
+func (m *Module) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		for name, value := range m.headers {
+			header.Set(name, value)
+		}
+
+		if m.config.CSP == nil {
+			return
+		}
+		nonce := ""
+		if m.config.CSP.UsesNonce() {
+			nonce = generateNonce()
+			c.Set(NONCE, nonce)
+			view.Set(c, "cspNonce", nonce)
+		}
+		c.Writer = &cspWriter{
+			ResponseWriter: c.Writer,
+			name:           m.config.CSP.HeaderName(),
+			policy:         m.config.CSP.String(nonce),
+		}
+	}
+}
+
+// GetNonce returns the CSP nonce of the request, empty when none was generated.
+func GetNonce(c *gin.Context) string {
+	return c.GetString(NONCE)
+}
+
+// cspWriter adds the policy header once the response turns out to be HTML.
+type cspWriter struct {
+	gin.ResponseWriter
+	name   string
+	policy string
+}
+
+func (w *cspWriter) addPolicy() {
+	if w.Written() {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if mediaType == "text/html" {
+		w.Header().Set(w.name, w.policy)
+	}
+}
+
+func (w *cspWriter) WriteHeaderNow() {
+	w.addPolicy()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cspWriter) Write(data []byte) (int, error) {
+	w.addPolicy()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cspWriter) WriteString(s string) (int, error) {
+	w.addPolicy()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func setHeader(headers map[string]string, name string, value string, defaultValue string) {
+	switch value {
+	case Disabled:
+	case "":
+		headers[name] = defaultValue
+	default:
+		headers[name] = value
+	}
+}
+
+func generateNonce() string {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return base64.RawStdEncoding.EncodeToString(nonce)
+}
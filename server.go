@@ -2,14 +2,28 @@ package routix
 
 import (
 	"fmt"
-	"net/http"
-	"path"
-	"reflect"
-	"runtime"
+	"io/fs"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/config"
+	"github.com/l1ttps/routix/contract"
+	"github.com/l1ttps/routix/cors"
+	"github.com/l1ttps/routix/csrf"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/gateway"
+	"github.com/l1ttps/routix/health"
+	"github.com/l1ttps/routix/i18n"
+	"github.com/l1ttps/routix/internal/funcname"
 	"github.com/l1ttps/routix/logger"
+	"github.com/l1ttps/routix/metrics"
+	"github.com/l1ttps/routix/payload"
+	"github.com/l1ttps/routix/security"
+	"github.com/l1ttps/routix/session"
+	"github.com/l1ttps/routix/static"
+	"github.com/l1ttps/routix/tracing"
+	"github.com/l1ttps/routix/view"
 )
 
 type ServerConfig struct {
@@ -18,12 +32,70 @@ type ServerConfig struct {
 	DebugLogger bool
 	PathRoot    string
 	BaseViewDir string
+	// Views renders the views of routix.Render. Defaults to an html/template
+	// engine loading BaseViewDir: a view.NewGlob engine for a glob such as
+	// "views/*.html", else a view.NewHTML engine for the directory. Use
+	// view.NewHTML with an embed.FS for single binaries.
+	Views view.Engine
+	// Static serves files of directories or embed.FS for requests matching no
+	// route, and optionally an SPA's index.html for unknown non-API paths.
+	Static []*static.Module
+	// NotFound handles requests matching no route nor static file, like a route
+	// handler. Defaults to returning exception.NotFoundException().
+	NotFound func(c *gin.Context) interface{}
+	// MethodNotAllowed handles requests whose path is routed for other methods
+	// only, like a route handler. The Allow header is set beforehand. Defaults
+	// to returning exception.MethodNotAllowedException().
+	MethodNotAllowed func(c *gin.Context) interface{}
+	// ErrorResponder writes the exceptions returned by handlers and fallbacks.
+	// Defaults to RespondError.
+	ErrorResponder func(c *gin.Context, httpException exception.HttpExceptionResponse)
+	// ErrorViews maps statuses to the views rendering exceptions for clients
+	// preferring HTML. The 0 entry is used for statuses without their own view.
+	ErrorViews map[int]string
+	Gateways   []*gateway.Gateway
+	// Tracing starts an OpenTelemetry span per request, with child spans around guards, interceptors and handlers.
+	Tracing *tracing.Module
+	// CORS answers preflight requests for every registered route and adds CORS
+	// headers to responses. Controllers can override it with cors.Override.
+	CORS *cors.Config
+	// Security adds HSTS, X-Content-Type-Options, X-Frame-Options, Referrer-Policy,
+	// COOP/CORP and a Content-Security-Policy on HTML responses.
+	Security *security.Module
+	// I18n resolves the locale of every request, translates exception and
+	// validation messages, and exposes a translate function to views.
+	I18n *i18n.Module
+	// Payload limits the size of request bodies, globally and per route with
+	// payload.Limit, and decompresses gzip, deflate and br bodies. Without it,
+	// only the limits set on routes are enforced.
+	Payload *payload.Module
+	// Sessions loads the session of every request, available with session.From in handlers and guards.
+	Sessions *session.Module
+	// CSRF issues tokens exposed to rendered views and validates them on unsafe
+	// methods. Controllers opt out with routix.Use(csrf.Skip()).
+	CSRF *csrf.Module
+	// Contract validates every response against an OpenAPI document in debug
+	// mode and reports the drift, such as undocumented statuses or fields.
+	Contract *contract.Module
+	// Metrics records per-route RED metrics and exposes them for Prometheus.
+	Metrics *metrics.Module
+	// Health mounts liveness and readiness endpoints. Readiness fails once Listen starts a graceful shutdown.
+	Health *health.Module
+	// ShutdownTimeout bounds how long Listen waits for in-flight requests. Defaults to 10 seconds.
+	ShutdownTimeout time.Duration
+	// ShutdownDelay is how long Listen keeps serving after readiness starts
+	// failing, so that load balancers see it fail before connections are
+	// refused. Defaults to 5 seconds with Health, 0 otherwise; a negative value
+	// disables it.
+	ShutdownDelay time.Duration
 }
 
 // CreateServer creates a new Gin server with the given configuration.
 // It takes a ServerConfig parameter that specifies the server's configuration.
 // The function returns a *gin.Engine, which is the created Gin server.
 func CreateServer(config ServerConfig) *gin.Engine {
+	createMu.Lock()
+	defer createMu.Unlock()
 
 	// Check if debug logger is enabled
 	if !config.DebugLogger {
@@ -32,13 +104,60 @@ func CreateServer(config ServerConfig) *gin.Engine {
 
 	// Create a new Gin server with default middleware
 	Driver = gin.Default()
+	registeredRoutes = nil
+	shutdownHooks = nil
+
+	// Attach the state of this server to its requests
+	state := &app{engine: Driver}
+	Driver.Use(func(c *gin.Context) {
+		c.Set(APP, state)
+	})
+
+	// Graceful shutdown settings used by Listen
+	ShutdownTimeout = config.ShutdownTimeout
+	if ShutdownTimeout == 0 {
+		ShutdownTimeout = defaultShutdownTimeout
+	}
+	ShutdownDelay = config.ShutdownDelay
+	if ShutdownDelay == 0 && config.Health != nil {
+		ShutdownDelay = defaultShutdownDelay
+	}
+	ShutdownDelay = max(ShutdownDelay, 0)
 
 	// Set default base view dir ("/views")
 
+	// Trace every request, including the global middlewares
+	useTracing(config.Tracing)
+
+	// Measure every request, including the global middlewares
+	useMetrics(config.Metrics)
+
+	// Validate the responses written by every middleware and handler
+	useContract(config.Contract)
+
+	// Add CORS headers before any global middleware can reject the request
+	useCORS(config.CORS)
+
+	// Add security headers to every response, including rejected ones
+	useSecurity(config.Security)
+
+	// Resolve the locale before any middleware can answer with an exception
+	useI18n(config.I18n)
+
+	// Limit and decompress request bodies before any middleware reads them
+	usePayload(config.Payload)
+
+	// Load sessions before CSRF validation, which may keep its tokens in them
+	useSessions(config.Sessions)
+
+	// Validate CSRF tokens before global middlewares act on the request
+	useCSRF(config.CSRF)
+
 	// Auto apply global middlewares
 	applyMiddlewares(Driver, config.Middlewares)
 
 	// Apply base path
+	PathRoot = "/"
 	if config.PathRoot != "" && config.PathRoot != "/" {
 		PathRoot = config.PathRoot
 	}
@@ -46,16 +165,65 @@ func CreateServer(config ServerConfig) *gin.Engine {
 	// Connect the controllers to the server
 	connectControllers(config.Controllers)
 
-	// Mapping views folder
-	useBaseViewDir(config.BaseViewDir)
+	// Mount the WebSocket gateways
+	connectGateways(config.Gateways)
+
+	// Mount the health endpoints
+	useHealth(config.Health)
+
+	// Answer preflight requests for every registered path
+	mountPreflights()
+
+	// Exempt routes marked with csrf.Skip
+	resolveCSRFSkips()
+
+	// Apply the body size limits of routes set with payload.Limit
+	resolvePayloadLimits()
+
+	// Load the views rendered by routix.Render
+	useViews(config.Views, config.BaseViewDir)
 
 	// Fallback
-	fallback()
+	fallback(config.NotFound, config.MethodNotAllowed)
+
+	// Capture the request-time state of this server
+	state.pathRoot = PathRoot
+	state.renderEnabled = IsEnableRender
+	state.errorViews = config.ErrorViews
+	state.errorResponder = config.ErrorResponder
+	if state.errorResponder == nil {
+		state.errorResponder = RespondError
+	}
+	if config.I18n != nil {
+		state.errorResponder = localizeErrors(state.errorResponder)
+	}
+	state.staticModules = config.Static
+	state.routes = Routes()
+	state.shutdownHooks = append([]func(){}, shutdownHooks...)
+	appsMu.Lock()
+	apps[Driver] = state
+	appsMu.Unlock()
 
 	// Return the created Gin server
 	return Driver
 }
 
+// ServerConfigFrom builds a ServerConfig from loaded configuration settings.
+//
+// Controllers and the other code-level options can then be set on the result:
+//
+//	serverConfig := routix.ServerConfigFrom(cfg.Server)
+//	serverConfig.Controllers = []routix.ControllerType{controllers.AppController}
+//	routix.CreateServer(serverConfig)
+//	routix.Listen(cfg.Server.Addr())
+func ServerConfigFrom(settings config.Server) ServerConfig {
+	return ServerConfig{
+		DebugLogger: settings.DebugLogger,
+		PathRoot:    settings.PathRoot,
+		BaseViewDir: settings.BaseViewDir,
+	}
+}
+
 // applyMiddlewares applies a list of middlewares to a gin.Engine.
 //
 // Parameters:
@@ -86,47 +254,153 @@ func connectControllers(controllers []ControllerType) {
 	return
 }
 
-// UseBaseViewDir sets the base view directory for loading HTML templates.
-//
-// customBaseViewDir: A string representing a custom base view directory. If
-// provided, the base view directory will be set to this value. Otherwise, the
-// default value "views/*" will be used.
+// connectGateways mounts the upgrade route of each gateway under PathRoot.
 //
-// No return value.
-func useBaseViewDir(customBaseViewDir string) {
-	if customBaseViewDir != "" && customBaseViewDir != "/" {
-		BaseViewDir = customBaseViewDir
-		Driver.LoadHTMLGlob(BaseViewDir)
-		IsEnableRender = true
+// The gateway middlewares run on the upgrade request, so guards reject a
+// connection before it is upgraded.
+func connectGateways(gateways []*gateway.Gateway) {
+	log := logger.Logger("Routix")
+
+	for _, gw := range gateways {
+		gatewayPath := joinPaths(PathRoot, gw.Path)
+		mountRoute(GET, gatewayPath, append(gw.Middlewares, gw.Handle)...)
+		log.Success(fmt.Sprintf("{%s} Mapped gateway", gatewayPath))
 	}
-	return
 }
 
-func getFunctionName(fcn interface{}) string {
-	pc := reflect.ValueOf(fcn).Pointer()
-	funcInfo := runtime.FuncForPC(pc)
-	if funcInfo == nil {
-		return ""
+// useHealth mounts the liveness and readiness endpoints of the health module
+// and makes readiness fail when a graceful shutdown starts.
+func useHealth(module *health.Module) {
+	if module == nil {
+		return
 	}
+	log := logger.Logger("Routix")
+
+	livePath := joinPaths(module.Path(), "/live")
+	readyPath := joinPaths(module.Path(), "/ready")
+	mountRoute(GET, livePath, module.Live)
+	mountRoute(GET, readyPath, module.Ready)
+	OnShutdown(module.SetShuttingDown)
 
-	funcName := path.Base(funcInfo.Name())
-	return funcName
+	log.Success(fmt.Sprintf("{%s} Mapped health endpoints {%s, %s}", module.Path(), livePath, readyPath))
 }
 
-func fallback() {
-	// Fallback method not allowed
-	Driver.NoMethod(func(c *gin.Context) {
-		c.JSON(http.StatusMethodNotAllowed, gin.H{
-			"status":  http.StatusMethodNotAllowed,
-			"message": http.StatusText(http.StatusMethodNotAllowed),
-		})
-	})
+// useSecurity applies the security headers middleware globally and mounts the
+// CSP report-collection endpoint when one is configured.
+func useSecurity(module *security.Module) {
+	if module == nil {
+		return
+	}
+	Driver.Use(module.Middleware())
+	if module.ReportPath() == "" {
+		return
+	}
+	log := logger.Logger("Routix")
+	// Browsers post reports without a CSRF token
+	mountRoute(POST, module.ReportPath(), csrf.Skip(), module.ReportHandler)
+	log.Success(fmt.Sprintf("{%s} Mapped CSP report endpoint", module.ReportPath()))
+}
 
-	// Fallback router not found
-	Driver.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"status":  http.StatusNotFound,
-			"message": http.StatusText(http.StatusNotFound),
-		})
-	})
+// useSessions applies the session middleware globally.
+func useSessions(module *session.Module) {
+	if module == nil {
+		return
+	}
+	Driver.Use(module.Middleware())
+}
+
+// useTracing applies the tracing middleware globally.
+func useTracing(module *tracing.Module) {
+	if module == nil {
+		return
+	}
+	Driver.Use(module.Middleware())
+}
+
+// useI18n applies the locale resolution middleware globally.
+func useI18n(module *i18n.Module) {
+	if module == nil {
+		return
+	}
+	log := logger.Logger("Routix")
+
+	Driver.Use(module.Middleware())
+	log.Success(fmt.Sprintf("{i18n} Serving locales %s", strings.Join(module.Catalog().Locales(), ", ")))
+}
+
+// localizeErrors returns responder writing exceptions with their messages
+// translated to the locale of the request.
+func localizeErrors(responder func(c *gin.Context, httpException exception.HttpExceptionResponse)) func(c *gin.Context, httpException exception.HttpExceptionResponse) {
+	return func(c *gin.Context, httpException exception.HttpExceptionResponse) {
+		responder(c, i18n.From(c).Exception(httpException))
+	}
+}
+
+// useContract applies the contract middleware globally when the module is enabled.
+func useContract(module *contract.Module) {
+	if module == nil || !module.Enabled() {
+		return
+	}
+	log := logger.Logger("Routix")
+
+	Driver.Use(module.Middleware())
+	log.Success(fmt.Sprintf("{contract} Validating responses against %d documented operations", len(module.Document().Operations())))
+}
+
+// useMetrics applies the metrics middleware globally and mounts the exposition endpoint.
+func useMetrics(module *metrics.Module) {
+	if module == nil {
+		return
+	}
+	log := logger.Logger("Routix")
+
+	Driver.Use(module.Middleware())
+	mountRoute(GET, module.Path(), module.Handler)
+
+	log.Success(fmt.Sprintf("{%s} Mapped metrics endpoint", module.Path()))
+}
+
+// mountRoute registers a framework route on Driver outside of a controller and
+// records it alongside the controller routes.
+func mountRoute(method HTTPMethod, absolutePath string, handlers ...gin.HandlerFunc) {
+	registeredRoutes = append(registeredRoutes, newRouteInfo(method, absolutePath, absolutePath, handlers))
+	Driver.Handle(string(method), absolutePath, handlers...)
+}
+
+// useViews loads the view engine and renders ctx.HTML through it.
+//
+// Without an engine, an html/template engine is created for customBaseViewDir:
+// a glob such as "views/*" is loaded as one set, and a directory with
+// layouts and partials. In debug
+// mode, engines exposing their files with FS() are re-loaded when they change.
+func useViews(engine view.Engine, customBaseViewDir string) {
+	IsEnableRender = false
+	if engine == nil {
+		if customBaseViewDir == "" || customBaseViewDir == "/" {
+			return
+		}
+		BaseViewDir = customBaseViewDir
+		engine = view.NewHTML(view.Dir(BaseViewDir), view.HTMLConfig{})
+		if strings.ContainsAny(BaseViewDir, "*?[") {
+			// A glob loads one shared set, as gin's LoadHTMLGlob does
+			engine = view.NewGlob(BaseViewDir)
+		}
+	}
+
+	// Reload changed views and show template errors in the browser while debugging
+	if watched, ok := engine.(interface{ FS() fs.FS }); ok && gin.IsDebugging() {
+		reloader := view.NewReloader(engine, watched.FS(), ViewReloadInterval)
+		OnShutdown(reloader.Close)
+		engine = reloader
+	}
+
+	if err := engine.Load(); err != nil {
+		panic(err)
+	}
+	Driver.HTMLRender = view.HTMLRender(engine)
+	IsEnableRender = true
+}
+
+func getFunctionName(fcn interface{}) string {
+	return funcname.Of(fcn)
 }
@@ -0,0 +1,213 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/logger"
+	"github.com/l1ttps/routix/view"
+)
+
+// Config holds the settings of the session module. Empty settings use the
+// defaults documented on each field.
+type Config struct {
+	// Store persists sessions. Defaults to a MemoryStore.
+	Store Store
+	// CookieName defaults to "session".
+	CookieName string
+	// CookiePath defaults to "/".
+	CookiePath   string
+	CookieDomain string
+	Secure       bool
+	// SameSite defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// MaxAge is the lifetime of a session. Defaults to 24 hours.
+	MaxAge time.Duration
+	// Rolling restarts MaxAge on every request, so sessions expire after
+	// MaxAge of inactivity instead of MaxAge after creation.
+	Rolling bool
+	// PrivilegeKeys are the values, such as "user_id" or "role", whose change
+	// regenerates the session.
+	PrivilegeKeys []string
+}
+
+// Module loads the session of every request and saves it before the response is written.
+type Module struct {
+	config Config
+}
+
+// New creates a session module. Mount it with ServerConfig.Sessions.
+func New(config Config) *Module {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.CookieName == "" {
+		config.CookieName = "session"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 24 * time.Hour
+	}
+	return &Module{config: config}
+}
+
+// Middleware loads the session into the context and exposes pending flash
+// messages to templates as flashes, a function consuming them when a template
+// calls it: {{range call .flashes}}.
+func (m *Module) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := m.load(c)
+		c.Set(SESSION, s)
+		view.Set(c, "flashes", s.Flashes)
+
+		writer := &sessionWriter{ResponseWriter: c.Writer, module: m, session: s, ctx: c}
+		c.Writer = writer
+		c.Next()
+		writer.save()
+	}
+}
+
+func (m *Module) load(c *gin.Context) *Session {
+	now := time.Now()
+	if token, err := c.Cookie(m.config.CookieName); err == nil {
+		record, err := m.config.Store.Load(c.Request.Context(), token)
+		if err != nil {
+			logger.Logger("Session").Error(fmt.Sprintf("Cannot load session: %s", err))
+		}
+		if record != nil && now.Before(record.ExpiresAt) {
+			s := &Session{module: m, record: record}
+			if m.config.Rolling {
+				record.ExpiresAt = now.Add(m.config.MaxAge)
+				s.modified = true
+			}
+			return s
+		}
+	}
+	return &Session{
+		module: m,
+		isNew:  true,
+		record: &Record{ID: newID(), CreatedAt: now, ExpiresAt: now.Add(m.config.MaxAge)},
+	}
+}
+
+// save persists the session and writes its cookie, once.
+func (m *Module) save(c *gin.Context, s *Session) {
+	ctx := c.Request.Context()
+	log := logger.Logger("Session")
+
+	if s.previousID != "" || s.destroyed {
+		previousID := s.previousID
+		if s.destroyed && previousID == "" && !s.isNew {
+			previousID = s.record.ID
+		}
+		if previousID != "" {
+			if err := m.config.Store.Delete(ctx, previousID); err != nil {
+				log.Error(fmt.Sprintf("Cannot delete session: %s", err))
+			}
+		}
+	}
+	if s.destroyed {
+		if !s.isNew {
+			m.setCookie(c, "", -1)
+		}
+		return
+	}
+	if !s.modified {
+		return
+	}
+
+	token, err := m.config.Store.Save(ctx, s.record)
+	if err != nil {
+		log.Error(fmt.Sprintf("Cannot save session: %s", err))
+		return
+	}
+	m.setCookie(c, token, int(time.Until(s.record.ExpiresAt).Seconds()))
+}
+
+func (m *Module) setCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   m.config.Secure,
+		HttpOnly: true,
+		SameSite: m.config.SameSite,
+	})
+}
+
+func (m *Module) isPrivilegeKey(key string) bool {
+	for _, privilegeKey := range m.config.PrivilegeKeys {
+		if privilegeKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionWriter saves the session right before the response headers are
+// written, since cookies cannot be set afterwards.
+type sessionWriter struct {
+	gin.ResponseWriter
+	module  *Module
+	session *Session
+	ctx     *gin.Context
+	saved   bool
+}
+
+func (w *sessionWriter) save() {
+	if w.saved || w.ResponseWriter.Written() {
+		return
+	}
+	w.saved = true
+	w.module.save(w.ctx, w.session)
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.save()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.save()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.save()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// CSRFStore keeps synchronizer CSRF tokens in the session. Use it as
+// csrf.Config.Store with the session module mounted.
+type CSRFStore struct{}
+
+// Load returns the token kept in the session of the request.
+func (CSRFStore) Load(c *gin.Context) (string, error) {
+	token, _ := Get[string](c, "_csrf")
+	return token, nil
+}
+
+// Save keeps token in the session of the request.
+func (CSRFStore) Save(c *gin.Context, token string) error {
+	From(c).Set("_csrf", token)
+	return nil
+}
+
+func newID() string {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(id)
+}
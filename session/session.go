@@ -0,0 +1,150 @@
+package session
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SESSION is the context key holding the *Session of the request.
+const SESSION string = "ROUTIX_SESSION"
+
+// Record is the persisted state of a session. Values round-trip through JSON,
+// so read them back with the typed Get.
+type Record struct {
+	ID        string         `json:"id"`
+	Values    map[string]any `json:"values,omitempty"`
+	Flashes   []Flash        `json:"flashes,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+// Flash is a message kept until it is read, usually by the next rendered view.
+type Flash struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+// Session is the session of the current request.
+type Session struct {
+	module     *Module
+	record     *Record
+	previousID string
+	isNew      bool
+	modified   bool
+	destroyed  bool
+}
+
+// From returns the session of the request. It panics when the session module
+// is not mounted, like a missing ServerConfig.Sessions would be a programming error.
+func From(c *gin.Context) *Session {
+	return c.MustGet(SESSION).(*Session)
+}
+
+// Get returns the value stored under key in the session of the request,
+// converted to T. ok is false when the key is absent or holds another type.
+//
+//	userID, ok := session.Get[int64](ctx, "user_id")
+func Get[T any](c *gin.Context, key string) (value T, ok bool) {
+	stored, exists := From(c).record.Values[key]
+	if !exists {
+		return value, false
+	}
+	if typed, isT := stored.(T); isT {
+		return typed, true
+	}
+	// Values loaded from a store are JSON types, such as float64 for numbers
+	encoded, err := json.Marshal(stored)
+	if err != nil || json.Unmarshal(encoded, &value) != nil {
+		return value, false
+	}
+	return value, true
+}
+
+// ID returns the identifier of the session. It changes when the session is regenerated.
+func (s *Session) ID() string {
+	return s.record.ID
+}
+
+// IsNew reports whether the session was created by the current request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Value returns the raw value stored under key.
+func (s *Session) Value(key string) (any, bool) {
+	value, exists := s.record.Values[key]
+	return value, exists
+}
+
+// Set stores value under key. Setting one of Config.PrivilegeKeys regenerates the session.
+func (s *Session) Set(key string, value any) {
+	if s.record.Values == nil {
+		s.record.Values = map[string]any{}
+	}
+	s.record.Values[key] = value
+	s.modified = true
+	if s.module.isPrivilegeKey(key) {
+		s.Regenerate()
+	}
+}
+
+// Delete removes key. Deleting one of Config.PrivilegeKeys regenerates the session.
+func (s *Session) Delete(key string) {
+	if _, exists := s.record.Values[key]; !exists {
+		return
+	}
+	delete(s.record.Values, key)
+	s.modified = true
+	if s.module.isPrivilegeKey(key) {
+		s.Regenerate()
+	}
+}
+
+// Clear removes every value.
+func (s *Session) Clear() {
+	s.record.Values = map[string]any{}
+	s.modified = true
+}
+
+// Regenerate gives the session a new ID, keeping its values, and drops the old
+// one from the store. Call it when privileges change, such as on login, to
+// prevent session fixation.
+func (s *Session) Regenerate() {
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.record.ID
+	}
+	s.record.ID = newID()
+	s.modified = true
+}
+
+// Destroy deletes the session from the store and expires its cookie.
+func (s *Session) Destroy() {
+	s.destroyed = true
+}
+
+// AddFlash keeps a message until it is read with Flashes.
+func (s *Session) AddFlash(category string, message string) {
+	s.record.Flashes = append(s.record.Flashes, Flash{Category: category, Message: message})
+	s.modified = true
+}
+
+// Flashes returns and removes the pending flash messages.
+func (s *Session) Flashes() []Flash {
+	flashes := s.record.Flashes
+	if len(flashes) > 0 {
+		s.record.Flashes = nil
+		s.modified = true
+	}
+	return flashes
+}
+
+// Authenticated returns a guard allowing requests whose session holds key,
+// for use with guard.UseGuard.
+func Authenticated(key string) func(c *gin.Context) bool {
+	return func(c *gin.Context) bool {
+		_, exists := From(c).Value(key)
+		return exists
+	}
+}
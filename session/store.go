@@ -0,0 +1,174 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCookieTooLarge is returned by the cookie store when an encoded session
+// exceeds the 4096 bytes browsers keep.
+var ErrCookieTooLarge = errors.New("session: encoded cookie exceeds 4096 bytes")
+
+// Store persists sessions. The token is the value of the session cookie.
+//
+// Server-side stores use the record ID as token; external backends such as
+// Redis implement Store the same way.
+type Store interface {
+	// Load returns the record for token, or nil when there is none.
+	Load(ctx context.Context, token string) (*Record, error)
+	// Save persists record and returns the token to put in the cookie.
+	Save(ctx context.Context, record *Record) (string, error)
+	// Delete removes the record stored under id.
+	Delete(ctx context.Context, id string) error
+}
+
+// CookieStore keeps the whole session in the cookie, encrypted and
+// authenticated with AES-GCM. It needs no server state, but a regenerated
+// or destroyed session cannot be revoked before it expires.
+type CookieStore struct {
+	aeads []cipher.AEAD
+}
+
+// NewCookieStore creates a cookie store. The first key encrypts new cookies;
+// the others are still accepted, so keys can be rotated.
+func NewCookieStore(keys ...[]byte) *CookieStore {
+	if len(keys) == 0 {
+		panic("session: NewCookieStore needs at least one key")
+	}
+	store := &CookieStore{}
+	for _, key := range keys {
+		derived := sha256.Sum256(key)
+		block, err := aes.NewCipher(derived[:])
+		if err != nil {
+			panic(err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+		store.aeads = append(store.aeads, aead)
+	}
+	return store
+}
+
+// Load decrypts the record from the cookie. Cookies that fail authentication are ignored.
+func (s *CookieStore) Load(ctx context.Context, token string) (*Record, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, nil
+	}
+	for _, aead := range s.aeads {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+		plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+		if err != nil {
+			continue
+		}
+		record := &Record{}
+		if err := json.Unmarshal(plain, record); err != nil {
+			return nil, nil
+		}
+		return record, nil
+	}
+	return nil, nil
+}
+
+// Save encrypts the record into the cookie value.
+func (s *CookieStore) Save(ctx context.Context, record *Record) (string, error) {
+	plain, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil))
+	if len(token) > 4096 {
+		return "", ErrCookieTooLarge
+	}
+	return token, nil
+}
+
+// Delete does nothing: the cookie holds the only copy of the session.
+func (s *CookieStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// MemoryStore keeps sessions in memory. Sessions are lost on restart and not
+// shared between replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]memoryRecord{}, lastSweep: time.Now()}
+}
+
+// Load returns the record stored under the token.
+func (s *MemoryStore) Load(ctx context.Context, token string) (*Record, error) {
+	s.mu.Lock()
+	stored, exists := s.records[token]
+	s.mu.Unlock()
+	if !exists || time.Now().After(stored.expiresAt) {
+		return nil, nil
+	}
+	record := &Record{}
+	// Decode a copy so handlers never share values between requests
+	return record, json.Unmarshal(stored.data, record)
+}
+
+// Save stores the record under its ID.
+func (s *MemoryStore) Save(ctx context.Context, record *Record) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = memoryRecord{data: data, expiresAt: record.ExpiresAt}
+	if now.Sub(s.lastSweep) > time.Minute {
+		for id, stored := range s.records {
+			if now.After(stored.expiresAt) {
+				delete(s.records, id)
+			}
+		}
+		s.lastSweep = now
+	}
+	return record.ID, nil
+}
+
+// Delete removes the record stored under id.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+	return nil
+}
+
+// Len returns the number of stored sessions, including expired ones not swept yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
@@ -0,0 +1,94 @@
+package routix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/l1ttps/routix/logger"
+)
+
+// defaultShutdownTimeout is the ShutdownTimeout of servers whose config sets none.
+const defaultShutdownTimeout = 10 * time.Second
+
+// defaultShutdownDelay is the ShutdownDelay of servers with readiness checks whose config sets none.
+const defaultShutdownDelay = 5 * time.Second
+
+// ShutdownTimeout bounds how long Listen waits for in-flight requests during a graceful shutdown.
+var ShutdownTimeout = defaultShutdownTimeout
+
+// ShutdownDelay is how long Listen keeps serving after the shutdown hooks ran,
+// so load balancers notice the failing readiness before connections are refused.
+var ShutdownDelay time.Duration = 0
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []func()
+)
+
+// OnShutdown registers a function called when a graceful shutdown starts,
+// before the server stops accepting connections.
+func OnShutdown(hook func()) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownHooks = append(shutdownHooks, hook)
+}
+
+// Listen serves the server created by CreateServer on addr and shuts it down
+// gracefully on SIGINT or SIGTERM.
+//
+// On shutdown it runs the OnShutdown hooks, waits ShutdownDelay, then stops
+// accepting connections and waits up to ShutdownTimeout for in-flight requests.
+func Listen(addr string) error {
+	log := logger.Logger("Routix")
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: Driver,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	log.Success("Listening on " + addr)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Warning("Shutting down gracefully")
+	runShutdownHooks()
+	time.Sleep(ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func runShutdownHooks() {
+	shutdownMu.Lock()
+	hooks := append([]func(){}, shutdownHooks...)
+	shutdownMu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
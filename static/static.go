@@ -0,0 +1,252 @@
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config holds the settings of a static mount. Empty settings use the defaults
+// documented on each field.
+type Config struct {
+	// Prefix is the URL path the files are served under. Defaults to "/".
+	Prefix string
+	// Index is the file served for directories and by the SPA fallback. Defaults to "index.html".
+	Index string
+	// SPA serves Index for unknown paths under Prefix requested by browsers,
+	// except paths under the server's PathRoot, which keep their 404.
+	SPA bool
+	// Exclude lists more path prefixes, such as "/api", left to the 404 handler by the SPA fallback.
+	Exclude []string
+	// Immutable reports whether a file name is content-hashed, such as
+	// "app.3f9a1c2e.js". Those files are cached for a year without revalidation,
+	// which clients cannot take back, so it is opt-in: set it to HashedName, or
+	// to a function matching the names of your bundler. Defaults to no file.
+	Immutable func(name string) bool
+	// MaxAge is how long other files are cached before revalidation with their
+	// ETag and Last-Modified. Defaults to 0: always revalidate.
+	MaxAge time.Duration
+}
+
+// Module serves the files of a file system, such as a directory or an embed.FS.
+type Module struct {
+	fsys   fs.FS
+	config Config
+	etags  sync.Map
+}
+
+// New creates a static mount serving fsys. Mount it with ServerConfig.Static.
+func New(fsys fs.FS, config Config) *Module {
+	if config.Prefix == "" {
+		config.Prefix = "/"
+	}
+	config.Prefix = "/" + strings.Trim(config.Prefix, "/")
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+	return &Module{fsys: fsys, config: config}
+}
+
+// Dir returns the files of a directory on disk.
+func Dir(path string) fs.FS {
+	return os.DirFS(path)
+}
+
+// Prefix returns the URL path the files are served under.
+func (m *Module) Prefix() string {
+	return m.config.Prefix
+}
+
+// Serve writes the file matching the request path. It returns false, without
+// writing anything, when there is none.
+func (m *Module) Serve(c *gin.Context) bool {
+	name, ok := m.fileName(c.Request.URL.Path)
+	if !ok {
+		return false
+	}
+	return m.serveFile(c, name)
+}
+
+// Fallback writes Index for requests the SPA router should handle: browser
+// navigations under Prefix that match no file and no excluded prefix.
+func (m *Module) Fallback(c *gin.Context) bool {
+	if !m.config.SPA || !strings.Contains(c.GetHeader("Accept"), "text/html") {
+		return false
+	}
+	if _, ok := m.fileName(c.Request.URL.Path); !ok {
+		return false
+	}
+	for _, exclude := range m.config.Exclude {
+		if HasPathPrefix(c.Request.URL.Path, exclude) {
+			return false
+		}
+	}
+	return m.serveFile(c, m.config.Index)
+}
+
+// fileName maps a URL path to the name of a file of fsys.
+func (m *Module) fileName(urlPath string) (string, bool) {
+	if !HasPathPrefix(urlPath, m.config.Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(urlPath, m.config.Prefix)), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+// serveFile writes a file, or the index of a directory, preferring a precompressed
+// variant the client accepts.
+func (m *Module) serveFile(c *gin.Context, name string) bool {
+	info, err := fs.Stat(m.fsys, name)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		name = path.Join(name, m.config.Index)
+		if info, err = fs.Stat(m.fsys, name); err != nil || info.IsDir() {
+			return false
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Add("Vary", "Accept-Encoding")
+	servedName, encoding := name, ""
+	for _, variant := range [][2]string{{".br", "br"}, {".gz", "gzip"}} {
+		if !acceptsEncoding(c.GetHeader("Accept-Encoding"), variant[1]) {
+			continue
+		}
+		if variantInfo, err := fs.Stat(m.fsys, name+variant[0]); err == nil && !variantInfo.IsDir() {
+			servedName, encoding, info = name+variant[0], variant[1], variantInfo
+			break
+		}
+	}
+
+	content, err := m.open(servedName)
+	if err != nil {
+		return false
+	}
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	if etag, err := m.etag(servedName, info); err == nil {
+		header.Set("ETag", etag)
+	}
+	if m.config.Immutable != nil && m.config.Immutable(path.Base(name)) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else if m.config.MaxAge > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(m.config.MaxAge.Seconds())))
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	// ServeContent answers conditional and range requests; a zero ModTime, as
+	// in embed.FS, omits Last-Modified and relies on the ETag.
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), content)
+	return true
+}
+
+// open returns a seekable reader of the file, reading it into memory when the
+// file system does not support seeking.
+func (m *Module) open(name string) (io.ReadSeeker, error) {
+	file, err := m.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		return seeker, nil
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// etag returns a strong ETag hashing the file content, cached per size and modification time.
+func (m *Module) etag(name string, info fs.FileInfo) (string, error) {
+	key := fmt.Sprintf("%s:%d:%d", name, info.Size(), info.ModTime().UnixNano())
+	if etag, exists := m.etags.Load(key); exists {
+		return etag.(string), nil
+	}
+	file, err := m.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	m.etags.Store(key, etag)
+	return etag, nil
+}
+
+// HashedName reports whether a file name carries a hex content hash before its
+// extension, such as "app.3f9a1c2e.js" or "index-7b2e9d41.css": a segment of
+// at least 8 lowercase hex digits, mixing digits and letters.
+func HashedName(name string) bool {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	cut := strings.LastIndexAny(base, ".-_")
+	if cut < 0 {
+		return false
+	}
+	segment := base[cut+1:]
+	if len(segment) < 8 {
+		return false
+	}
+	hasDigit, hasLetter := false, false
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'a' && r <= 'f':
+			hasLetter = true
+		default:
+			return false
+		}
+	}
+	return hasDigit && hasLetter
+}
+
+// HasPathPrefix reports whether urlPath is prefix or below it.
+func HasPathPrefix(urlPath string, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows encoding.
+func acceptsEncoding(header string, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		params = strings.ReplaceAll(params, " ", "")
+		return params != "q=0" && params != "q=0.0" && params != "q=0.00" && params != "q=0.000"
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package routix
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+)
+
+// FileResponse serves a file from disk, with support for Range and conditional requests.
+type FileResponse struct {
+	path       string
+	filename   string
+	attachment bool
+}
+
+// File creates a FileResponse serving the file at path inline.
+//
+// A missing file responds with a NotFoundException.
+func File(path string) *FileResponse {
+	return &FileResponse{path: path}
+}
+
+// Attachment creates a FileResponse that asks the client to download the file at path as filename.
+//
+// When filename is empty the base name of path is used.
+func Attachment(path string, filename string) *FileResponse {
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+	return &FileResponse{path: path, filename: filename, attachment: true}
+}
+
+// Respond writes the file. It implements Responder.
+func (f *FileResponse) Respond(c *gin.Context) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		respondException(c, exception.NotFoundException())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		respondException(c, exception.NotFoundException())
+		return
+	}
+
+	if f.attachment {
+		c.Header("Content-Disposition", contentDisposition("attachment", f.filename))
+	}
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+}
+
+// StreamResponse copies a reader to the client without buffering it.
+type StreamResponse struct {
+	reader      io.Reader
+	contentType string
+	status      int
+	filename    string
+}
+
+// Stream creates a StreamResponse copying reader to the client with the given content type.
+//
+// The reader is closed after the response when it implements io.Closer.
+func Stream(reader io.Reader, contentType string) *StreamResponse {
+	return &StreamResponse{reader: reader, contentType: contentType}
+}
+
+// Status sets the status code of the stream. It defaults to the route's HttpCode or 200.
+func (s *StreamResponse) Status(status int) *StreamResponse {
+	s.status = status
+	return s
+}
+
+// Attachment asks the client to download the stream as filename.
+func (s *StreamResponse) Attachment(filename string) *StreamResponse {
+	s.filename = filename
+	return s
+}
+
+// Respond copies the stream. It implements Responder.
+func (s *StreamResponse) Respond(c *gin.Context) {
+	if closer, ok := s.reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	status := s.status
+	if status == 0 {
+		status = DefaultStatus(c)
+	}
+	if s.contentType != "" {
+		c.Header("Content-Type", s.contentType)
+	}
+	if s.filename != "" {
+		c.Header("Content-Disposition", contentDisposition("attachment", s.filename))
+	}
+	c.Status(status)
+	c.Writer.WriteHeaderNow()
+
+	io.Copy(flushWriter{c.Writer}, s.reader)
+}
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID sets the client's last event ID.
+	ID string
+	// Event is the event type. Clients receive untyped events as "message".
+	Event string
+	// Data is the payload. Strings are sent as-is, other values as JSON.
+	Data any
+	// Retry tells the client how long to wait before reconnecting, in milliseconds.
+	Retry uint
+}
+
+// SSEResponse streams Server-Sent Events from a channel until the channel is
+// closed or the client disconnects.
+type SSEResponse struct {
+	events    <-chan Event
+	heartbeat time.Duration
+	onClose   func()
+}
+
+// DefaultSSEHeartbeat is the interval between keepalive comments sent on idle SSE streams.
+var DefaultSSEHeartbeat = 15 * time.Second
+
+// SSE creates an SSEResponse that sends every event received on events.
+func SSE(events <-chan Event) *SSEResponse {
+	return &SSEResponse{events: events, heartbeat: DefaultSSEHeartbeat}
+}
+
+// Heartbeat sets the interval between keepalive comments. Zero disables them.
+func (s *SSEResponse) Heartbeat(interval time.Duration) *SSEResponse {
+	s.heartbeat = interval
+	return s
+}
+
+// OnClose registers a function called once the stream ends, either because
+// the channel was closed or because the client disconnected. Producers use it
+// to stop sending.
+func (s *SSEResponse) OnClose(fn func()) *SSEResponse {
+	s.onClose = fn
+	return s
+}
+
+// Respond streams the events. It implements Responder.
+func (s *SSEResponse) Respond(c *gin.Context) {
+	if s.onClose != nil {
+		defer s.onClose()
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", sse.ContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	var heartbeat <-chan time.Time
+	if s.heartbeat > 0 {
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-s.events:
+			if !ok {
+				return
+			}
+			err := sse.Encode(c.Writer, sse.Event{
+				Id:    event.ID,
+				Event: event.Event,
+				Data:  event.Data,
+				Retry: event.Retry,
+			})
+			if err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// flushWriter flushes after every write so streamed data reaches the client immediately.
+type flushWriter struct {
+	writer gin.ResponseWriter
+}
+
+func (w flushWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.writer.Flush()
+	return n, err
+}
+
+// contentDisposition formats a Content-Disposition header, falling back to a
+// plain quoted filename when it cannot be encoded (RFC 6266).
+func contentDisposition(disposition string, filename string) string {
+	if value := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); value != "" {
+		return value
+	}
+	return fmt.Sprintf("%s; filename=%q", disposition, filename)
+}
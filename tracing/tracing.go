@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/exception"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TRACER is the context key holding the tracer of the request, set by the tracing middleware.
+const TRACER string = "ROUTIX_TRACER"
+
+// instrumentationName identifies routix as the instrumentation library of its spans.
+const instrumentationName = "github.com/l1ttps/routix"
+
+// Config holds the settings of the tracing module.
+type Config struct {
+	// TracerProvider creates the tracer. Defaults to the global provider.
+	TracerProvider trace.TracerProvider
+	// Propagator extracts the incoming trace context. Defaults to W3C traceparent and baggage.
+	Propagator propagation.TextMapPropagator
+}
+
+// Module starts a server span for every request and enables the child spans
+// routix creates around guards, interceptors, handlers and serialization.
+type Module struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New creates a tracing module. Mount it with ServerConfig.Tracing.
+func New(config Config) *Module {
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.Propagator == nil {
+		config.Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return &Module{
+		tracer:     config.TracerProvider.Tracer(instrumentationName),
+		propagator: config.Propagator,
+	}
+}
+
+// Middleware starts the server span of the request, named by its route
+// template, continuing the trace of the incoming traceparent header.
+func (m *Module) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx := m.propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := m.tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		c.Set(TRACER, m.tracer)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if value, exists := c.Get(exception.EXCEPTION); exists {
+			RecordException(span, value.(exception.HttpExceptionResponse))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+	}
+}
+
+// Start starts a child span of the request span. When the tracing module is
+// not mounted it returns the request context and a no-op span.
+//
+// The returned context should replace the request context for the duration of
+// the span, as Span does, so that nested spans are parented correctly.
+func Start(c *gin.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	value, exists := c.Get(TRACER)
+	if !exists {
+		return c.Request.Context(), trace.SpanFromContext(context.Background())
+	}
+	return value.(trace.Tracer).Start(c.Request.Context(), name, trace.WithAttributes(attributes...))
+}
+
+// Span runs fn inside a child span of the request span, with the request
+// context carrying the span while fn runs. fn receives the span to record its
+// outcome on it. Values fn adds to the request context are kept after the span.
+func Span(c *gin.Context, name string, fn func(span trace.Span), attributes ...attribute.KeyValue) {
+	ctx, span := Start(c, name, attributes...)
+	if _, exists := c.Get(TRACER); !exists {
+		fn(span)
+		return
+	}
+	defer span.End()
+
+	parent := trace.SpanFromContext(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+	defer func() {
+		// Keep the request fn leaves, such as one carrying the user set by a
+		// guard, with the request span in place of the child span
+		c.Request = c.Request.WithContext(trace.ContextWithSpan(c.Request.Context(), parent))
+	}()
+	fn(span)
+}
+
+// RecordException records an exception event on span, using the exception
+// name as its type.
+func RecordException(span trace.Span, httpException exception.HttpExceptionResponse) {
+	span.RecordError(httpException, trace.WithAttributes(
+		attribute.String("exception.type", httpException.Name()),
+		attribute.Int("http.response.status_code", httpException.Status),
+	))
+}
+
+// Inject writes the trace context of ctx into header, to propagate it on outgoing requests.
+func (m *Module) Inject(ctx context.Context, header http.Header) {
+	m.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,21 @@
+// Package tracingtest records the spans of the tracing module in memory, for
+// tests, without adding the OpenTelemetry SDK to production builds.
+package tracingtest
+
+import (
+	"github.com/l1ttps/routix/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewInMemory creates a tracing module that records finished spans in memory,
+// for asserting on spans in tests.
+//
+//	module, exporter := tracingtest.NewInMemory()
+//	// ... serve requests ...
+//	spans := exporter.GetSpans()
+func NewInMemory() (*tracing.Module, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return tracing.New(tracing.Config{TracerProvider: provider}), exporter
+}
@@ -0,0 +1,153 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNotFound is returned by storages opening a key they do not hold.
+var ErrNotFound = errors.New("upload: file not found")
+
+// Storage persists uploaded files. The key identifies the stored file.
+//
+// Object stores such as S3 implement Storage the same way as the built-in
+// disk and memory storages.
+type Storage interface {
+	// Save stores content, the bytes of file, and returns its key.
+	Save(ctx context.Context, file *File, content io.Reader) (string, error)
+	// Open returns the content stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+// DiskStorage stores files in a directory, under random names keeping the
+// extension of their detected type.
+type DiskStorage struct {
+	dir string
+}
+
+// NewDiskStorage creates a disk storage writing to dir, created when missing.
+func NewDiskStorage(dir string) *DiskStorage {
+	return &DiskStorage{dir: dir}
+}
+
+// Save writes content to a new file of the directory.
+func (s *DiskStorage) Save(ctx context.Context, file *File, content io.Reader) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", err
+	}
+	key, err := randomKey(file)
+	if err != nil {
+		return "", err
+	}
+	target, err := os.OpenFile(filepath.Join(s.dir, key), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(target, content); err != nil {
+		target.Close()
+		os.Remove(target.Name())
+		return "", err
+	}
+	return key, target.Close()
+}
+
+// Open opens the file stored under key.
+func (s *DiskStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.Path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the file stored under key.
+func (s *DiskStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.Path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Path returns the path of the file stored under key.
+func (s *DiskStorage) Path(key string) string {
+	// Keys never hold separators, so this cannot leave the directory
+	return filepath.Join(s.dir, filepath.Base(key))
+}
+
+// MemoryStorage keeps files in memory, for tests and small temporary files.
+type MemoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemoryStorage creates an in-memory storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{files: map[string][]byte{}}
+}
+
+// Save reads content into memory.
+func (s *MemoryStorage) Save(ctx context.Context, file *File, content io.Reader) (string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+	key, err := randomKey(file)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[key] = data
+	return key, nil
+}
+
+// Open returns a reader of the content stored under key.
+func (s *MemoryStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, exists := s.Bytes(key)
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes the content stored under key.
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, key)
+	return nil
+}
+
+// Bytes returns the content stored under key.
+func (s *MemoryStorage) Bytes(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, exists := s.files[key]
+	return data, exists
+}
+
+// Len returns the number of stored files.
+func (s *MemoryStorage) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
+
+// randomKey returns a random file name with the extension of the detected type of file.
+func randomKey(file *File) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random) + file.Extension, nil
+}
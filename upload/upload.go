@@ -0,0 +1,366 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"github.com/l1ttps/routix/csrf"
+	"github.com/l1ttps/routix/exception"
+	"github.com/l1ttps/routix/metadata"
+	"github.com/l1ttps/routix/payload"
+	"github.com/l1ttps/routix/pipe"
+)
+
+// FILES is the context key holding the files uploaded with the request.
+const FILES string = "ROUTIX_UPLOAD_FILES"
+
+// VALUES is the context key holding the other form fields of the request.
+const VALUES string = "ROUTIX_UPLOAD_VALUES"
+
+// sniffLength is the number of leading bytes the content type is detected from.
+const sniffLength = 3072
+
+// partOverhead bounds the size of the boundary and headers of a part.
+const partOverhead = 4 << 10
+
+// Config holds the settings of an upload route. Empty settings use the
+// defaults documented on each field.
+type Config struct {
+	// Storage stores the uploaded files. Required.
+	Storage Storage
+	// MaxFileSize is the largest size of a file, in bytes. Defaults to 10 MiB.
+	MaxFileSize int64
+	// MaxFiles is the largest number of files of a request. Defaults to 10.
+	MaxFiles int
+	// MaxFormSize is the largest total size of the other form fields, in bytes. Defaults to 1 MiB.
+	MaxFormSize int64
+	// AllowedTypes lists the accepted content types, detected from the file
+	// content, such as "application/pdf" or "image/*". Empty accepts any type.
+	AllowedTypes []string
+	// Fields lists the form fields that may hold files. Empty accepts any field.
+	Fields []string
+}
+
+// File describes an uploaded file.
+type File struct {
+	// Field is the form field of the file.
+	Field string `json:"field"`
+	// Filename is the base name of the file on the client.
+	Filename string `json:"filename"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// ContentType is the type detected from the content, such as "image/png".
+	ContentType string `json:"contentType"`
+	// DeclaredType is the type sent by the client, which may not match the content.
+	DeclaredType string `json:"declaredType,omitempty"`
+	// Extension is the extension of the detected type, such as ".png".
+	Extension string `json:"extension"`
+	// Key is the key of the file in the storage.
+	Key string `json:"key"`
+
+	storage Storage
+}
+
+// Open returns the content of the file from the storage.
+func (f *File) Open(ctx context.Context) (io.ReadCloser, error) {
+	return f.storage.Open(ctx, f.Key)
+}
+
+// Delete removes the file from the storage.
+func (f *File) Delete(ctx context.Context) error {
+	return f.storage.Delete(ctx, f.Key)
+}
+
+// Multipart returns a route middleware streaming the files of a
+// multipart/form-data body to the storage, without buffering them:
+//
+//	routix.Post("/avatars", uploadAvatar, upload.Multipart(upload.Config{
+//		Storage:      upload.NewDiskStorage("uploads"),
+//		MaxFileSize:  2 << 20,
+//		AllowedTypes: []string{"image/png", "image/jpeg"},
+//	}))
+//
+// Files larger than MaxFileSize answer with a PayloadTooLargeException, files of
+// types missing from AllowedTypes with an UnsupportedMediaTypeException, and the
+// handler is skipped, as with failing pipes. The files stored before the
+// failure are deleted. Handlers read the files with Files and FormFile, and
+// the other fields with FormValue.
+//
+// The route accepts bodies as large as the limits of config allow, raising the
+// body size limit of ServerConfig.Payload; a payload.Limit placed after
+// Multipart overrides it. With ServerConfig.CSRF, the token of HTML forms is
+// read from the form field as the body streams, and must come before the files.
+func Multipart(config Config) gin.HandlerFunc {
+	if config.Storage == nil {
+		panic("upload: Config.Storage is required")
+	}
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = 10 << 20
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = 10
+	}
+	if config.MaxFormSize <= 0 {
+		config.MaxFormSize = 1 << 20
+	}
+
+	handler := func(c *gin.Context) {
+		// Only the first failure of a request is reported
+		if _, failed := pipe.Failure(c); failed {
+			return
+		}
+		files, values, err := receive(c, config)
+		if err != nil {
+			for _, file := range files {
+				file.Delete(context.WithoutCancel(c.Request.Context()))
+			}
+			c.Set(pipe.FAILED, bodyError(err))
+			return
+		}
+		c.Set(FILES, files)
+		c.Set(VALUES, values)
+	}
+	metadata.Attach(handler, csrf.STREAMED, true)
+	return metadata.Attach(handler, payload.LIMIT, bodyLimit(config))
+}
+
+// bodyLimit returns the size of the largest body config accepts.
+func bodyLimit(config Config) int64 {
+	const maxInt64 = 1<<63 - 1
+	perFile := config.MaxFileSize + partOverhead
+	if perFile > (maxInt64-config.MaxFormSize)/int64(config.MaxFiles+1) {
+		return payload.Unlimited
+	}
+	// The other fields are counted as one more part
+	return perFile*int64(config.MaxFiles+1) + config.MaxFormSize
+}
+
+// receive reads the parts of the multipart body, storing the files. On error
+// it also returns the files stored so far, for the caller to delete.
+func receive(c *gin.Context, config Config) ([]*File, url.Values, error) {
+	reader, err := c.Request.MultipartReader()
+	if errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil, exception.UnsupportedMediaTypeException("Expected a multipart/form-data body")
+	}
+	if err != nil {
+		return nil, nil, exception.BadRequestException("Invalid multipart body: " + err.Error())
+	}
+
+	var files []*File
+	values := url.Values{}
+	formBudget := config.MaxFormSize
+	pending, deferred := csrf.Deferred(c)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			if deferred {
+				return files, nil, exception.ForbiddenException(csrf.ErrMissingToken.Error())
+			}
+			return files, values, nil
+		}
+		if err != nil {
+			return files, nil, bodyError(err)
+		}
+		field := part.FormName()
+		if field == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, formBudget+1))
+			part.Close()
+			if err != nil {
+				return files, nil, bodyError(err)
+			}
+			formBudget -= int64(len(value))
+			if formBudget < 0 {
+				return files, nil, exception.PayloadTooLargeException(fmt.Sprintf("Form fields exceed %d bytes", config.MaxFormSize))
+			}
+			if deferred && field == pending.Field {
+				if err := pending.Check(string(value)); err != nil {
+					return files, nil, exception.ForbiddenException(err.Error())
+				}
+				deferred = false
+				continue
+			}
+			values.Add(field, string(value))
+			continue
+		}
+
+		// Nothing is stored before the CSRF token is checked
+		if deferred {
+			part.Close()
+			return files, nil, exception.ForbiddenException(csrf.ErrMissingToken.Error())
+		}
+
+		if len(config.Fields) > 0 && !contains(config.Fields, field) {
+			part.Close()
+			return files, nil, exception.BadRequestException(fmt.Sprintf("Unexpected file field %q", field))
+		}
+		if len(files) == config.MaxFiles {
+			part.Close()
+			return files, nil, exception.PayloadTooLargeException(fmt.Sprintf("Too many files, at most %d are accepted", config.MaxFiles))
+		}
+		file, err := store(c.Request.Context(), config, part)
+		part.Close()
+		if err != nil {
+			return files, nil, err
+		}
+		files = append(files, file)
+	}
+}
+
+// store detects the type of a file part, checks it and streams the part to the storage.
+func store(ctx context.Context, config Config, part *multipart.Part) (*File, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, bodyError(err)
+	}
+	head = head[:n]
+
+	detected := mimetype.Detect(head)
+	file := &File{
+		Field: part.FormName(),
+		// Some clients send the full path of the file, with either separator
+		Filename:     path.Base(strings.ReplaceAll(part.FileName(), "\\", "/")),
+		DeclaredType: part.Header.Get("Content-Type"),
+		ContentType:  detected.String(),
+		Extension:    detected.Extension(),
+		storage:      config.Storage,
+	}
+	if !allowed(detected, config.AllowedTypes) {
+		mediaType, _, _ := mime.ParseMediaType(file.ContentType)
+		return nil, exception.UnsupportedMediaTypeException(fmt.Sprintf("File %q of type %s is not allowed", file.Filename, mediaType))
+	}
+
+	content := &limitedReader{reader: io.MultiReader(bytes.NewReader(head), part), remaining: config.MaxFileSize}
+	key, err := config.Storage.Save(ctx, file, content)
+	switch {
+	case content.err != nil:
+		if err == nil {
+			config.Storage.Delete(context.WithoutCancel(ctx), key)
+		}
+		if errors.Is(content.err, errFileTooLarge) {
+			return nil, exception.PayloadTooLargeException(fmt.Sprintf("File %q exceeds %d bytes", file.Filename, config.MaxFileSize))
+		}
+		return nil, bodyError(content.err)
+	case err != nil:
+		return nil, exception.InternalServerErrorException("Cannot store the uploaded file: " + err.Error())
+	}
+	file.Key, file.Size = key, content.read
+	return file, nil
+}
+
+// allowed reports whether the detected type matches one of the allowed types,
+// "image/*" matching every image type.
+func allowed(detected *mimetype.MIME, allowedTypes []string) bool {
+	if len(allowedTypes) == 0 {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(detected.String())
+	for _, allowedType := range allowedTypes {
+		if prefix, wildcard := strings.CutSuffix(allowedType, "/*"); wildcard {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if allowedType == "*/*" || detected.Is(allowedType) {
+			return true
+		}
+	}
+	return false
+}
+
+var errFileTooLarge = errors.New("upload: file too large")
+
+// limitedReader reads at most remaining bytes, failing with errFileTooLarge
+// beyond, and records the error returned to the storage.
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	read      int64
+	err       error
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		r.err = errFileTooLarge
+		return n, r.err
+	}
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// bodyError turns an error reading the body into the exception answering the request.
+func bodyError(err error) exception.HttpExceptionResponse {
+	var httpException exception.HttpExceptionResponse
+	if errors.As(err, &httpException) {
+		return httpException
+	}
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return exception.PayloadTooLargeException(fmt.Sprintf("Request body exceeds %d bytes", maxBytesError.Limit))
+	}
+	return exception.BadRequestException("Invalid multipart body: " + err.Error())
+}
+
+// Files returns the files uploaded with the request, in the order of the body.
+func Files(c *gin.Context) []*File {
+	files, _ := c.Get(FILES)
+	list, _ := files.([]*File)
+	return list
+}
+
+// FormFile returns the first file uploaded in the form field.
+func FormFile(c *gin.Context, field string) (*File, bool) {
+	for _, file := range Files(c) {
+		if file.Field == field {
+			return file, true
+		}
+	}
+	return nil, false
+}
+
+// FormValue returns the first value of the form field name.
+func FormValue(c *gin.Context, name string) string {
+	return FormValues(c).Get(name)
+}
+
+// FormValues returns the form fields of the request that are not files.
+func FormValues(c *gin.Context) url.Values {
+	values, _ := c.Get(VALUES)
+	form, _ := values.(url.Values)
+	return form
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
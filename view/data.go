@@ -0,0 +1,74 @@
+package view
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DATA is the context key holding the values exposed to the templates rendered for a request.
+const DATA string = "ROUTIX_VIEW_DATA"
+
+// Lazy is a value computed when a view is rendered, for values that are costly
+// to create, such as CSRF tokens. Values consumed by reading them, such as
+// flash messages, are rather exposed as functions templates call where they
+// use them, with {{call .name}}.
+type Lazy func() any
+
+// Set exposes value under key to the templates rendered for the current request.
+//
+// Modules use it to hand per-request values such as the CSP nonce or the CSRF
+// token to templates. Values returned by the handler take precedence.
+func Set(c *gin.Context, key string, value any) {
+	data, exists := c.Get(DATA)
+	if !exists {
+		data = map[string]any{}
+		c.Set(DATA, data)
+	}
+	data.(map[string]any)[key] = value
+}
+
+// Data returns the values exposed with Set for the current request.
+func Data(c *gin.Context) map[string]any {
+	data, exists := c.Get(DATA)
+	if !exists {
+		return map[string]any{}
+	}
+	return data.(map[string]any)
+}
+
+// Merge returns the template data for a handler's return value: when it is a
+// map with string keys (such as gin.H), a copy with the values exposed with Set
+// added and Lazy values computed. Other values, such as structs, are returned
+// unchanged, without the exposed values: views reading csrfField, cspNonce or
+// flashes need map data.
+func Merge(c *gin.Context, response any) any {
+	exposed := Data(c)
+	if len(exposed) == 0 {
+		return response
+	}
+
+	var value reflect.Value
+	if response != nil {
+		value = reflect.ValueOf(response)
+		if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+			return response
+		}
+	}
+
+	merged := gin.H{}
+	for key, exposedValue := range exposed {
+		if lazy, ok := exposedValue.(Lazy); ok {
+			exposedValue = lazy()
+		}
+		merged[key] = exposedValue
+	}
+	if response == nil {
+		return merged
+	}
+	iter := value.MapRange()
+	for iter.Next() {
+		merged[iter.Key().String()] = iter.Value().Interface()
+	}
+	return merged
+}
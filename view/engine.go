@@ -0,0 +1,86 @@
+package view
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// Engine loads and renders the templates of the views.
+//
+// HTMLEngine, built on html/template, is the default. Other template languages
+// plug in by implementing Engine and setting it as ServerConfig.Views.
+type Engine interface {
+	// Load parses the templates. It is called once at startup.
+	Load() error
+	// Render writes the template name executed with data.
+	Render(w io.Writer, name string, data any) error
+}
+
+// HTMLRender adapts engine to gin, so that ctx.HTML renders through it.
+func HTMLRender(engine Engine) render.HTMLRender {
+	return htmlRender{engine: engine}
+}
+
+type htmlRender struct {
+	engine Engine
+}
+
+func (r htmlRender) Instance(name string, data any) render.Render {
+	return instance{engine: r.engine, name: name, data: data}
+}
+
+type instance struct {
+	engine Engine
+	name   string
+	data   any
+}
+
+// Render renders into a buffer first, so a failing template writes nothing.
+// A failing render answers 500; errors located by a Reloader are answered with
+// the developer error page.
+func (i instance) Render(w http.ResponseWriter) error {
+	i.WriteContentType(w)
+	var buffer bytes.Buffer
+	if err := i.engine.Render(&buffer, i.name, i.data); err != nil {
+		var templateErr *TemplateError
+		w.WriteHeader(http.StatusInternalServerError)
+		if !errors.As(err, &templateErr) {
+			return err
+		}
+		return WriteErrorPage(w, templateErr)
+	}
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+func (i instance) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "text/html; charset=utf-8")
+	}
+}
+
+// Dir returns the views of a directory on disk. A glob such as "views/*" is
+// accepted for compatibility with BaseViewDir: the directory is the part
+// before the first pattern segment.
+func Dir(path string) fs.FS {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			segments = segments[:index]
+			break
+		}
+	}
+	dir := strings.Join(segments, "/")
+	if dir == "" {
+		dir = "."
+	}
+	return os.DirFS(dir)
+}
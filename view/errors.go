@@ -0,0 +1,99 @@
+package view
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateError is a template error located in its file.
+type TemplateError struct {
+	// File is the view the error is in, empty when it cannot be located.
+	File string
+	// Line is the line of the error in File, 0 when unknown.
+	Line int
+	// Source holds the lines around Line.
+	Source []SourceLine
+	Err    error
+}
+
+// SourceLine is a numbered line of a view.
+type SourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// templateLocation matches "template: users/index.html:12:" in html/template errors.
+var templateLocation = regexp.MustCompile(`template: ([^:\s]+):(\d+)`)
+
+func newTemplateError(fsys fs.FS, err error) error {
+	var templateErr *TemplateError
+	if errors.As(err, &templateErr) {
+		return err
+	}
+	templateErr = &TemplateError{Err: err}
+
+	match := templateLocation.FindStringSubmatch(err.Error())
+	if match == nil {
+		return templateErr
+	}
+	templateErr.File = match[1]
+	templateErr.Line, _ = strconv.Atoi(match[2])
+
+	content, readErr := fs.ReadFile(fsys, templateErr.File)
+	if readErr != nil {
+		return templateErr
+	}
+	lines := strings.Split(string(content), "\n")
+	for number := templateErr.Line - 5; number <= templateErr.Line+5; number++ {
+		if number < 1 || number > len(lines) {
+			continue
+		}
+		templateErr.Source = append(templateErr.Source, SourceLine{
+			Number:  number,
+			Text:    lines[number-1],
+			Current: number == templateErr.Line,
+		})
+	}
+	return templateErr
+}
+
+func (e *TemplateError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Template error</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2933; }
+h1 { color: #c53030; font-size: 1.4rem; }
+pre { background: #f5f7fa; padding: 1rem; overflow-x: auto; }
+.current { background: #fed7d7; }
+.number { color: #9aa5b1; user-select: none; }
+</style>
+</head>
+<body>
+<h1>Template error{{ if .File }} in {{ .File }}{{ if .Line }}:{{ .Line }}{{ end }}{{ end }}</h1>
+<pre>{{ .Err }}</pre>
+{{ if .Source }}<pre>{{ range .Source }}<span{{ if .Current }} class="current"{{ end }}><span class="number">{{ printf "%4d" .Number }}</span>  {{ .Text }}</span>
+{{ end }}</pre>{{ end }}
+</body>
+</html>
+`))
+
+// WriteErrorPage writes the developer error page of err.
+func WriteErrorPage(w io.Writer, err *TemplateError) error {
+	return errorPage.Execute(w, err)
+}
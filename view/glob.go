@@ -0,0 +1,62 @@
+package view
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// GlobEngine renders the html/template files matching a glob pattern, such as
+// "views/*.html", parsed into one set as gin's LoadHTMLGlob does: views are
+// named by their base name, such as "index.html", and use the templates
+// defined by every other file. It is the engine of ServerConfig.BaseViewDir.
+type GlobEngine struct {
+	pattern string
+	funcs   template.FuncMap
+	mu      sync.RWMutex
+	set     *template.Template
+}
+
+// NewGlob creates an engine loading the templates matching pattern.
+func NewGlob(pattern string) *GlobEngine {
+	return &GlobEngine{pattern: pattern, funcs: template.FuncMap{}}
+}
+
+// Funcs adds functions available to every template. Call it before Load.
+func (e *GlobEngine) Funcs(funcs template.FuncMap) *GlobEngine {
+	for name, fn := range funcs {
+		e.funcs[name] = fn
+	}
+	return e
+}
+
+// FS returns the directory of the pattern, watched for changes while debugging.
+func (e *GlobEngine) FS() fs.FS {
+	return Dir(e.pattern)
+}
+
+// Load parses every template matching the pattern. On error the templates
+// loaded before are kept.
+func (e *GlobEngine) Load() error {
+	set, err := template.New("").Funcs(e.funcs).ParseGlob(e.pattern)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.set = set
+	e.mu.Unlock()
+	return nil
+}
+
+// Render executes the template name.
+func (e *GlobEngine) Render(w io.Writer, name string, data any) error {
+	e.mu.RLock()
+	set := e.set
+	e.mu.RUnlock()
+	if set == nil || set.Lookup(name) == nil {
+		return fmt.Errorf("view: cannot find view %q", name)
+	}
+	return set.ExecuteTemplate(w, name, data)
+}
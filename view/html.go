@@ -0,0 +1,197 @@
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// HTMLConfig holds the settings of the html/template engine. Empty settings
+// use the defaults documented on each field.
+type HTMLConfig struct {
+	// Extensions lists the extensions of template files. Defaults to .html, .tmpl and .gohtml.
+	Extensions []string
+	// LayoutDir holds the layouts. Defaults to "layouts".
+	LayoutDir string
+	// PartialDir holds the partials shared by every view. Defaults to "partials".
+	PartialDir string
+	// Layout is the layout views are rendered in, such as "base.html" for
+	// layouts/base.html. Empty renders views on their own.
+	Layout string
+	// Funcs are the functions available to every template.
+	Funcs template.FuncMap
+	// Delims overrides the "{{" and "}}" action delimiters.
+	Delims [2]string
+}
+
+// HTMLEngine renders html/template views with layouts and partials.
+//
+// Views are named by their path relative to the root, such as "users/index.html".
+// Every view is parsed together with the layouts and partials, so a view fills
+// the blocks of its layout:
+//
+//	<!-- layouts/base.html -->
+//	<main>{{ block "content" . }}{{ end }}</main>
+//
+//	<!-- users/index.html -->
+//	{{ define "content" }}{{ template "partials/user.html" .user }}{{ end }}
+//
+// A view picks another layout with {{ define "layout" }}admin.html{{ end }},
+// or none with {{ define "layout" }}none{{ end }}.
+type HTMLEngine struct {
+	fsys   fs.FS
+	config HTMLConfig
+	mu     sync.RWMutex
+	views  map[string]*template.Template
+}
+
+// NewHTML creates an html/template engine loading views from fsys, such as an
+// embed.FS or Dir("views").
+func NewHTML(fsys fs.FS, config HTMLConfig) *HTMLEngine {
+	if len(config.Extensions) == 0 {
+		config.Extensions = []string{".html", ".tmpl", ".gohtml"}
+	}
+	if config.LayoutDir == "" {
+		config.LayoutDir = "layouts"
+	}
+	if config.PartialDir == "" {
+		config.PartialDir = "partials"
+	}
+	return &HTMLEngine{fsys: fsys, config: config, views: map[string]*template.Template{}}
+}
+
+// Funcs adds functions available to every template. Call it before Load.
+func (e *HTMLEngine) Funcs(funcs template.FuncMap) *HTMLEngine {
+	if e.config.Funcs == nil {
+		e.config.Funcs = template.FuncMap{}
+	}
+	for name, fn := range funcs {
+		e.config.Funcs[name] = fn
+	}
+	return e
+}
+
+// FS returns the file system the views are loaded from.
+func (e *HTMLEngine) FS() fs.FS {
+	return e.fsys
+}
+
+// Load parses every view, layout and partial. On error the views loaded
+// before are kept.
+func (e *HTMLEngine) Load() error {
+	shared, views, err := e.files()
+	if err != nil {
+		return err
+	}
+
+	base := template.New("").Funcs(e.config.Funcs)
+	if e.config.Delims[0] != "" {
+		base.Delims(e.config.Delims[0], e.config.Delims[1])
+	}
+	for _, name := range shared {
+		if err := e.parse(base, name); err != nil {
+			return err
+		}
+	}
+
+	loaded := map[string]*template.Template{}
+	for _, name := range shared {
+		loaded[name] = base
+	}
+	for _, name := range views {
+		set, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		if err := e.parse(set, name); err != nil {
+			return err
+		}
+		loaded[name] = set
+	}
+
+	e.mu.Lock()
+	e.views = loaded
+	e.mu.Unlock()
+	return nil
+}
+
+// Render executes the view name, inside its layout when it has one.
+func (e *HTMLEngine) Render(w io.Writer, name string, data any) error {
+	e.mu.RLock()
+	set, exists := e.views[name]
+	e.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("view: cannot find view %q", name)
+	}
+
+	// Layouts and partials render on their own, such as fragments for htmx
+	if e.isShared(name) {
+		return set.ExecuteTemplate(w, name, data)
+	}
+
+	layout := e.config.Layout
+	if override := set.Lookup("layout"); override != nil {
+		var buffer bytes.Buffer
+		if err := override.Execute(&buffer, nil); err != nil {
+			return err
+		}
+		layout = strings.TrimSpace(buffer.String())
+	}
+	if layout == "" || layout == "none" {
+		return set.ExecuteTemplate(w, name, data)
+	}
+
+	if set.Lookup(layout) == nil {
+		layout = path.Join(e.config.LayoutDir, layout)
+	}
+	if set.Lookup(layout) == nil {
+		return fmt.Errorf("view: cannot find layout %q for view %q", layout, name)
+	}
+	return set.ExecuteTemplate(w, layout, data)
+}
+
+// files lists the layouts and partials, then the views.
+func (e *HTMLEngine) files() (shared []string, views []string, err error) {
+	err = fs.WalkDir(e.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !e.hasExtension(name) {
+			return nil
+		}
+		if e.isShared(name) {
+			shared = append(shared, name)
+		} else {
+			views = append(views, name)
+		}
+		return nil
+	})
+	return shared, views, err
+}
+
+func (e *HTMLEngine) parse(set *template.Template, name string) error {
+	content, err := fs.ReadFile(e.fsys, name)
+	if err != nil {
+		return err
+	}
+	_, err = set.New(name).Parse(string(content))
+	return err
+}
+
+func (e *HTMLEngine) isShared(name string) bool {
+	return strings.HasPrefix(name, e.config.LayoutDir+"/") || strings.HasPrefix(name, e.config.PartialDir+"/")
+}
+
+func (e *HTMLEngine) hasExtension(name string) bool {
+	for _, extension := range e.config.Extensions {
+		if path.Ext(name) == extension {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package view
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l1ttps/routix/logger"
+)
+
+// Reloader re-loads an engine whenever the files of its views change. It polls
+// the file system, which works the same for directories, mounted volumes and
+// editors that replace files on save.
+//
+// Load and render errors are returned as *TemplateError, which the HTMLRender
+// adapter shows as a developer error page. Use it in development only.
+type Reloader struct {
+	engine   Engine
+	fsys     fs.FS
+	interval time.Duration
+	once     sync.Once
+	stop     chan struct{}
+
+	mu        sync.RWMutex
+	loadErr   error
+	signature string
+}
+
+// NewReloader watches fsys every interval and re-loads engine on change.
+// Polling starts with the first Load.
+func NewReloader(engine Engine, fsys fs.FS, interval time.Duration) *Reloader {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Reloader{engine: engine, fsys: fsys, interval: interval, stop: make(chan struct{})}
+}
+
+// Load loads the engine and starts watching. A failing load is not returned:
+// it is shown by Render until the files are fixed.
+func (r *Reloader) Load() error {
+	r.reload(r.scan())
+	r.once.Do(func() { go r.watch() })
+	return nil
+}
+
+// Render renders through the engine, or returns the error of the last load.
+func (r *Reloader) Render(w io.Writer, name string, data any) error {
+	r.mu.RLock()
+	loadErr := r.loadErr
+	r.mu.RUnlock()
+	if loadErr != nil {
+		return loadErr
+	}
+	if err := r.engine.Render(w, name, data); err != nil {
+		return newTemplateError(r.fsys, err)
+	}
+	return nil
+}
+
+// Close stops watching.
+func (r *Reloader) Close() {
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+}
+
+func (r *Reloader) watch() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			signature := r.scan()
+			r.mu.RLock()
+			changed := signature != r.signature
+			r.mu.RUnlock()
+			if changed {
+				r.reload(signature)
+			}
+		}
+	}
+}
+
+func (r *Reloader) reload(signature string) {
+	log := logger.Logger("View")
+	err := r.engine.Load()
+	if err != nil {
+		err = newTemplateError(r.fsys, err)
+		log.Error(fmt.Sprintf("Cannot load views: %s", err))
+	} else if r.signature != "" {
+		log.Success("Reloaded views")
+	}
+
+	r.mu.Lock()
+	r.loadErr = err
+	r.signature = signature
+	r.mu.Unlock()
+}
+
+// scan summarizes the name, size and modification time of every file.
+func (r *Reloader) scan() string {
+	var signature strings.Builder
+	fs.WalkDir(r.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			fmt.Fprintf(&signature, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+	return signature.String()
+}